@@ -0,0 +1,218 @@
+package containerd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"discover/config"
+	"discover/models"
+)
+
+// criNamespace is the containerd namespace the CRI plugin keeps Kubernetes containers in
+const criNamespace = "k8s.io"
+
+// crictl runs a crictl command against the configured containerd socket
+func crictl(args ...string) ([]byte, error) {
+	if socket := config.Current().Containerd.Socket; socket != "" {
+		args = append([]string{"--runtime-endpoint", "unix://" + socket}, args...)
+	}
+
+	output, err := exec.Command("crictl", args...).Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return nil, fmt.Errorf("crictl %s failed: %v\n%s", strings.Join(args, " "), err, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, fmt.Errorf("crictl failed: %v", err)
+	}
+	return output, nil
+}
+
+// criMetadata is the metadata of a CRI pod sandbox or container
+type criMetadata struct {
+	Name      string `json:"name"`
+	UID       string `json:"uid"`
+	Namespace string `json:"namespace"`
+	Attempt   int    `json:"attempt"`
+}
+
+// GetPodSandboxes returns the CRI pod sandboxes on this node
+func GetPodSandboxes() ([]models.PodSandbox, error) {
+	output, err := crictl("pods", "-o", "json")
+	if err != nil {
+		return nil, fmt.Errorf("error listing pod sandboxes: %v", err)
+	}
+
+	var list struct {
+		Items []struct {
+			ID        string      `json:"id"`
+			Metadata  criMetadata `json:"metadata"`
+			State     string      `json:"state"`
+			CreatedAt string      `json:"createdAt"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(output, &list); err != nil {
+		return nil, fmt.Errorf("error parsing pod sandboxes: %v", err)
+	}
+
+	sandboxes := make([]models.PodSandbox, 0, len(list.Items))
+	for _, item := range list.Items {
+		sandboxes = append(sandboxes, models.PodSandbox{
+			ID:        item.ID,
+			Name:      item.Metadata.Name,
+			Namespace: item.Metadata.Namespace,
+			UID:       item.Metadata.UID,
+			State:     criState(item.State, "SANDBOX_"),
+			Created:   criTime(item.CreatedAt),
+		})
+	}
+	return sandboxes, nil
+}
+
+// criContainers returns the containers known to the CRI plugin, with the pod they belong to
+func criContainers() ([]models.RuntimeContainer, error) {
+	output, err := crictl("ps", "-a", "-o", "json")
+	if err != nil {
+		return nil, err
+	}
+
+	var list struct {
+		Containers []struct {
+			ID           string      `json:"id"`
+			PodSandboxID string      `json:"podSandboxId"`
+			Metadata     criMetadata `json:"metadata"`
+			Image        struct {
+				Image string `json:"image"`
+			} `json:"image"`
+			State     string            `json:"state"`
+			CreatedAt string            `json:"createdAt"`
+			Labels    map[string]string `json:"labels"`
+		} `json:"containers"`
+	}
+	if err := json.Unmarshal(output, &list); err != nil {
+		return nil, fmt.Errorf("error parsing CRI containers: %v", err)
+	}
+
+	containers := make([]models.RuntimeContainer, 0, len(list.Containers))
+	for _, item := range list.Containers {
+		containers = append(containers, models.RuntimeContainer{
+			ID:           item.ID,
+			Name:         item.Metadata.Name,
+			Image:        item.Image.Image,
+			State:        criState(item.State, "CONTAINER_"),
+			Namespace:    criNamespace,
+			Created:      criTime(item.CreatedAt),
+			PodSandboxID: item.PodSandboxID,
+			PodName:      item.Labels["io.kubernetes.pod.name"],
+			PodNamespace: item.Labels["io.kubernetes.pod.namespace"],
+			Attempt:      item.Metadata.Attempt,
+		})
+	}
+	return containers, nil
+}
+
+// criImages returns the images pulled through the CRI plugin
+func criImages() ([]models.RuntimeImage, error) {
+	output, err := crictl("images", "-o", "json")
+	if err != nil {
+		return nil, err
+	}
+
+	var list struct {
+		Images []struct {
+			ID       string   `json:"id"`
+			RepoTags []string `json:"repoTags"`
+			Size     string   `json:"size"`
+		} `json:"images"`
+	}
+	if err := json.Unmarshal(output, &list); err != nil {
+		return nil, fmt.Errorf("error parsing CRI images: %v", err)
+	}
+
+	images := make([]models.RuntimeImage, 0, len(list.Images))
+	for _, item := range list.Images {
+		size, _ := strconv.ParseUint(item.Size, 10, 64)
+		images = append(images, models.RuntimeImage{ID: item.ID, Tags: item.RepoTags, Size: size, Namespace: criNamespace})
+	}
+	return images, nil
+}
+
+// criLogPath asks the CRI plugin where a container's log file is
+func criLogPath(id string) (string, error) {
+	output, err := crictl("inspect", "-o", "json", id)
+	if err != nil {
+		return "", err
+	}
+
+	var inspect struct {
+		Status struct {
+			LogPath string `json:"logPath"`
+		} `json:"status"`
+	}
+	if err := json.Unmarshal(output, &inspect); err != nil {
+		return "", fmt.Errorf("error parsing container %s: %v", id, err)
+	}
+	if inspect.Status.LogPath == "" {
+		return "", fmt.Errorf("container %s has no log path", id)
+	}
+	return inspect.Status.LogPath, nil
+}
+
+// readCRILog reads the last lines of a CRI log file, whose lines look like
+// "2026-10-19T02:00:00.123456789Z stdout F message". Lines tagged P are partial and are joined
+// with the lines that follow them.
+func readCRILog(path string, lines int) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("error reading log %s: %v", path, err)
+	}
+	defer file.Close()
+
+	var entries []string
+	partial := ""
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		fields := strings.SplitN(scanner.Text(), " ", 4)
+		if len(fields) < 4 {
+			continue
+		}
+		if fields[2] == "P" {
+			partial += fields[3]
+			continue
+		}
+
+		timestamp := fields[0]
+		if parsed, err := time.Parse(time.RFC3339Nano, fields[0]); err == nil {
+			timestamp = parsed.Local().Format("2006-01-02 15:04:05")
+		}
+		entries = append(entries, fmt.Sprintf("%s %s %s", timestamp, fields[1], partial+fields[3]))
+		partial = ""
+		if len(entries) > lines {
+			entries = entries[1:]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("error reading log %s: %v", path, err)
+	}
+	return strings.Join(entries, "\n"), nil
+}
+
+// criState turns a CRI state such as "CONTAINER_RUNNING" into "running"
+func criState(state, prefix string) string {
+	return strings.ToLower(strings.TrimPrefix(state, prefix))
+}
+
+// criTime parses a CRI timestamp, given in nanoseconds since the epoch
+func criTime(nanoseconds string) time.Time {
+	value, err := strconv.ParseInt(nanoseconds, 10, 64)
+	if err != nil || value == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, value)
+}
@@ -0,0 +1,270 @@
+package cron
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"discover/agents/systemd"
+	"discover/models"
+)
+
+const (
+	// systemCrontab is the main system crontab
+	systemCrontab = "/etc/crontab"
+	// systemCronDir holds package and admin crontabs
+	systemCronDir = "/etc/cron.d"
+	// RecentRuns is how many past executions are kept per job
+	RecentRuns = 5
+	// journalLines is how many cron journal entries are searched for executions
+	journalLines = 5000
+)
+
+// userSpoolDirs hold per-user crontabs named after the user: Debian uses the first, Red Hat
+// and Arch the second. Both are only readable by root.
+var userSpoolDirs = []string{"/var/spool/cron/crontabs", "/var/spool/cron"}
+
+// syslogFiles are searched for executions when there is no journal
+var syslogFiles = []string{"/var/log/cron", "/var/log/syslog"}
+
+var (
+	// cronCommandPattern matches the execution messages of cron and cronie, e.g. "(root) CMD (backup.sh)"
+	cronCommandPattern = regexp.MustCompile(`\((\S+)\) CMD \((.*)\)\s*$`)
+	// syslogLinePattern matches "Oct 19 02:00:01 host CRON[123]: message" and the RFC 3339 variant;
+	// cronie logs as CROND
+	syslogLinePattern = regexp.MustCompile(`^(\w{3} [ \d]\d \d\d:\d\d:\d\d|\d{4}-\d\d-\d\dT\S+) \S+ (?:CRON|CROND|crond)\[\d+\]: (.*)$`)
+	// environmentPattern matches variable assignments such as "MAILTO=root" or "PATH = /bin"
+	environmentPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*\s*=`)
+)
+
+// GetCronJobs returns the jobs of the system crontab, /etc/cron.d and the user crontabs, with
+// their next run and recent executions. User crontabs of other users are only read as root.
+func GetCronJobs() []models.CronJob {
+	var jobs []models.CronJob
+
+	systemFiles := []string{systemCrontab}
+	if entries, err := ioutil.ReadDir(systemCronDir); err == nil {
+		for _, entry := range entries {
+			// cron skips backup and package manager leftovers such as "job.dpkg-old" or "job~"
+			if !entry.IsDir() && !strings.ContainsAny(entry.Name(), ".~") {
+				systemFiles = append(systemFiles, filepath.Join(systemCronDir, entry.Name()))
+			}
+		}
+	}
+	for _, file := range systemFiles {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				fmt.Printf("Warning: Failed to read %s: %v\n", file, err)
+			}
+			continue
+		}
+		jobs = append(jobs, parseCrontab(string(data), file, "")...)
+	}
+
+	jobs = append(jobs, userCronJobs()...)
+
+	runs := recentExecutions()
+	now := time.Now()
+	for i := range jobs {
+		if schedule, err := ParseSchedule(jobs[i].Schedule); err == nil {
+			jobs[i].NextRun = schedule.Next(now)
+		}
+		jobs[i].Runs = runs[executionKey(jobs[i].User, jobs[i].Command)]
+	}
+	return jobs
+}
+
+// userCronJobs reads every user's crontab from the spool, or only the current user's with
+// `crontab -l` when the spool cannot be read
+func userCronJobs() []models.CronJob {
+	for _, dir := range userSpoolDirs {
+		entries, err := ioutil.ReadDir(dir)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			// Not root
+			break
+		}
+		var jobs []models.CronJob
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+			file := filepath.Join(dir, entry.Name())
+			data, err := ioutil.ReadFile(file)
+			if err != nil {
+				continue
+			}
+			jobs = append(jobs, parseCrontab(string(data), file, entry.Name())...)
+		}
+		return jobs
+	}
+
+	current, err := user.Current()
+	if err != nil {
+		return nil
+	}
+	// Exits with an error when the user has no crontab
+	output, err := exec.Command("crontab", "-l").Output()
+	if err != nil {
+		return nil
+	}
+	return parseCrontab(string(output), "crontab -l", current.Username)
+}
+
+// parseCrontab parses the jobs of a crontab. System crontabs (owner "") have a user field
+// between the schedule and the command; user crontabs run as their owner.
+func parseCrontab(content, source, owner string) []models.CronJob {
+	var jobs []models.CronJob
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || environmentPattern.MatchString(line) {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		scheduleFields := 5
+		if strings.HasPrefix(fields[0], "@") {
+			scheduleFields = 1
+		}
+		commandField := scheduleFields
+		if owner == "" {
+			commandField++
+		}
+		if len(fields) <= commandField {
+			continue
+		}
+
+		job := models.CronJob{
+			Source:   source,
+			User:     owner,
+			Schedule: strings.Join(fields[:scheduleFields], " "),
+			Command:  commandText(line, commandField),
+		}
+		if owner == "" {
+			job.User = fields[scheduleFields]
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs
+}
+
+// commandText returns the rest of a line from its nth field on, keeping the command's own spacing
+func commandText(line string, n int) string {
+	rest := line
+	for i := 0; i < n; i++ {
+		rest = strings.TrimLeft(rest, " \t")
+		end := strings.IndexAny(rest, " \t")
+		if end == -1 {
+			return ""
+		}
+		rest = rest[end:]
+	}
+	return strings.TrimSpace(rest)
+}
+
+// executionKey identifies the executions of a job in cron's log messages
+func executionKey(user, command string) string {
+	return user + "\x00" + command
+}
+
+// recentExecutions reads cron's "(user) CMD (command)" messages from the journal, or from
+// syslog files when there is no journal, keeping the last RecentRuns of each job
+func recentExecutions() map[string][]time.Time {
+	runs := make(map[string][]time.Time)
+	record := func(timestamp time.Time, message string) {
+		match := cronCommandPattern.FindStringSubmatch(message)
+		if match == nil {
+			return
+		}
+		key := executionKey(match[1], match[2])
+		runs[key] = append(runs[key], timestamp)
+		if len(runs[key]) > RecentRuns {
+			runs[key] = runs[key][1:]
+		}
+	}
+
+	entries, err := systemd.GetJournal([]string{"SYSLOG_IDENTIFIER=CRON", "SYSLOG_IDENTIFIER=CROND", "SYSLOG_IDENTIFIER=crond"},
+		systemd.JournalQuery{AllBoots: true, Lines: journalLines})
+	if err == nil {
+		for _, entry := range entries {
+			record(entry.Timestamp, entry.Message)
+		}
+		return runs
+	}
+
+	for _, file := range syslogFiles {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			if match := syslogLinePattern.FindStringSubmatch(line); match != nil {
+				record(parseSyslogTime(match[1]), match[2])
+			}
+		}
+		break
+	}
+	return runs
+}
+
+// parseSyslogTime parses a syslog timestamp. Traditional timestamps have no year, so the most
+// recent matching date is assumed.
+func parseSyslogTime(text string) time.Time {
+	if timestamp, err := time.Parse(time.RFC3339Nano, text); err == nil {
+		return timestamp
+	}
+
+	now := time.Now()
+	timestamp, err := time.ParseInLocation("Jan _2 15:04:05", text, now.Location())
+	if err != nil {
+		return time.Time{}
+	}
+	timestamp = timestamp.AddDate(now.Year(), 0, 0)
+	if timestamp.After(now.Add(24 * time.Hour)) {
+		timestamp = timestamp.AddDate(-1, 0, 0)
+	}
+	return timestamp
+}
+
+// GetAtJobs returns the jobs queued with at, as listed by atq (all users' jobs as root)
+func GetAtJobs() ([]models.AtJob, error) {
+	output, err := exec.Command("atq").Output()
+	if err != nil {
+		return nil, fmt.Errorf("error listing at jobs: %v", err)
+	}
+
+	var jobs []models.AtJob
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		// Lines look like "12	Mon Oct 20 10:00:00 2026 a alice"
+		fields := strings.Fields(line)
+		if len(fields) < 8 {
+			continue
+		}
+		runAt, _ := time.ParseInLocation("Mon Jan _2 15:04:05 2006", strings.Join(fields[1:6], " "), time.Local)
+		jobs = append(jobs, models.AtJob{ID: fields[0], RunAt: runAt, Queue: fields[6], User: fields[7]})
+	}
+
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].RunAt.Before(jobs[j].RunAt) })
+	return jobs, nil
+}
+
+// GetAtJobScript returns the script an at job will run, including the environment it restores
+func GetAtJobScript(id string) (string, error) {
+	output, err := exec.Command("at", "-c", id).Output()
+	if err != nil {
+		return "", fmt.Errorf("error reading at job %s: %v", id, err)
+	}
+	return string(output), nil
+}
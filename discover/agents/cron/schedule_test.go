@@ -0,0 +1,101 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSchedule(t *testing.T) {
+	tests := []struct {
+		expression string
+		valid      bool
+	}{
+		{"* * * * *", true},
+		{"*/5 * * * 1-5", true},
+		{"0 9-17/2 * * mon-fri", true},
+		{"30 4 1,15 jan,Jul *", true},
+		{"0 0 * * 7", true},
+		{"@daily", true},
+		{"@Weekly", true},
+		{"@reboot", false},
+		{"* * * *", false},
+		{"60 * * * *", false},
+		{"* 24 * * *", false},
+		{"* * 0 * *", false},
+		{"* * * 13 *", false},
+		{"* * * * 8", false},
+		{"*/0 * * * *", false},
+		{"5-1 * * * *", false},
+		{"* * * foo *", false},
+	}
+
+	for _, test := range tests {
+		_, err := ParseSchedule(test.expression)
+		if test.valid && err != nil {
+			t.Errorf("ParseSchedule(%q): unexpected error %v", test.expression, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("ParseSchedule(%q): expected an error", test.expression)
+		}
+	}
+}
+
+func TestScheduleNext(t *testing.T) {
+	// Wednesday, 2026-10-14 10:07
+	from := time.Date(2026, 10, 14, 10, 7, 30, 0, time.UTC)
+
+	tests := []struct {
+		expression string
+		want       time.Time
+	}{
+		{"* * * * *", time.Date(2026, 10, 14, 10, 8, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2026, 10, 14, 10, 15, 0, 0, time.UTC)},
+		{"5/20 * * * *", time.Date(2026, 10, 14, 10, 25, 0, 0, time.UTC)},
+		{"0 9-17/4 * * *", time.Date(2026, 10, 14, 13, 0, 0, 0, time.UTC)},
+		{"0 12 * * *", time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2026, 10, 14, 11, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)},
+		{"@yearly", time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+		// Names, case-insensitive, for months and weekdays
+		{"0 0 1 Feb *", time.Date(2027, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 8 * * sat", time.Date(2026, 10, 17, 8, 0, 0, 0, time.UTC)},
+		{"0 8 * * MON-tue", time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)},
+		// Sunday is both 0 and 7
+		{"0 0 * * 0", time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)},
+		// Steps over "*" in a day field keep checking that field
+		{"0 0 */2 * *", time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)},
+		{"0 0 */10 * *", time.Date(2026, 10, 21, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * */2", time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * */3", time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)},
+		// With "*" in one day field both must match: a 1st or 21st that is a Friday
+		{"0 0 */20 * 5", time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+		// With both day fields restricted either may match: the 20th or any Friday
+		{"0 0 20 * 5", time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)},
+		{"0 0 15 * mon", time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)},
+		// Leap days
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, test := range tests {
+		schedule, err := ParseSchedule(test.expression)
+		if err != nil {
+			t.Errorf("ParseSchedule(%q): %v", test.expression, err)
+			continue
+		}
+		if got := schedule.Next(from); !got.Equal(test.want) {
+			t.Errorf("%q: next run %s, want %s", test.expression, got, test.want)
+		}
+	}
+}
+
+func TestScheduleNeverRuns(t *testing.T) {
+	schedule, err := ParseSchedule("0 0 30 2 *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next := schedule.Next(time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC)); !next.IsZero() {
+		t.Errorf("February 30th: got next run %s, want none", next)
+	}
+}
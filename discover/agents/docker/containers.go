@@ -0,0 +1,34 @@
+package docker
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// ContainerNamesByID maps the full IDs of all containers, running or stopped, to their names
+func ContainerNamesByID() (map[string]string, error) {
+	output, err := exec.Command("docker", "ps", "--all", "--no-trunc", "--format", "{{.ID}}|{{.Names}}").Output()
+	if err != nil {
+		return nil, fmt.Errorf("error listing Docker containers: %v", err)
+	}
+
+	names := make(map[string]string)
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		parts := strings.SplitN(line, "|", 2)
+		if len(parts) == 2 {
+			names[parts[0]] = parts[1]
+		}
+	}
+	return names, nil
+}
+
+// GetContainerLogs retrieves the recent logs of any container by name or ID,
+// whether or not it belongs to a Compose project
+func GetContainerLogs(container string) string {
+	output, err := exec.Command("docker", "logs", "--tail", "100", container).CombinedOutput()
+	if err != nil {
+		return fmt.Sprintf("Error retrieving logs for container %s: %v", container, err)
+	}
+	return string(output)
+}
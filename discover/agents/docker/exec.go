@@ -0,0 +1,88 @@
+package docker
+
+import (
+	"fmt"
+	"net"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"discover/sessions"
+)
+
+// shellCommand prefers bash and falls back to sh for minimal images
+const shellCommand = "command -v bash >/dev/null 2>&1 && exec bash || exec sh"
+
+// OpenContainerShell starts an interactive shell in a container of a Docker Compose project
+// and returns once it exits
+func OpenContainerShell(projectName string, containerName string) error {
+	baseCmd, args := GetComposeCommand()
+	if baseCmd == "" {
+		return fmt.Errorf("neither 'docker compose' nor 'docker-compose' is available")
+	}
+
+	cmdArgs := append(args, "-p", projectName, "exec", containerName, "sh", "-c", shellCommand)
+	cmd := exec.Command(baseCmd, cmdArgs...)
+	if err := sessions.RunInteractive(cmd); err != nil {
+		return fmt.Errorf("shell session for container %s in project %s ended with error: %v",
+			containerName, projectName, err)
+	}
+	return nil
+}
+
+// PortForwardContainer forwards a local port to a port on a container of a Docker Compose project,
+// so unpublished ports can be reached. In the foreground it returns when the user presses Ctrl+C;
+// in the background it is tracked as a session.
+func PortForwardContainer(projectName string, containerName string, localPort, remotePort int, background bool) error {
+	ip, err := getContainerIP(projectName, containerName)
+	if err != nil {
+		return err
+	}
+
+	remoteAddr := net.JoinHostPort(ip, strconv.Itoa(remotePort))
+	stop, done, err := sessions.ForwardTCP(localPort, remoteAddr)
+	if err != nil {
+		return err
+	}
+
+	if background {
+		sessions.Register("docker port-forward", projectName+"/"+containerName, localPort, remotePort, stop, done)
+		return nil
+	}
+
+	fmt.Printf("Forwarding localhost:%d to container %s (%s), press Ctrl+C to stop\n",
+		localPort, containerName, remoteAddr)
+	sessions.WaitForInterrupt()
+	return stop()
+}
+
+// getContainerIP finds the IP address of a project's container on its first network
+func getContainerIP(projectName string, containerName string) (string, error) {
+	baseCmd, args := GetComposeCommand()
+	if baseCmd == "" {
+		return "", fmt.Errorf("neither 'docker compose' nor 'docker-compose' is available")
+	}
+
+	cmdArgs := append(args, "-p", projectName, "ps", "-q", containerName)
+	output, err := exec.Command(baseCmd, cmdArgs...).Output()
+	if err != nil {
+		return "", fmt.Errorf("error finding container %s in project %s: %v", containerName, projectName, err)
+	}
+
+	ids := strings.Fields(string(output))
+	if len(ids) == 0 {
+		return "", fmt.Errorf("container %s in project %s is not running", containerName, projectName)
+	}
+
+	output, err = exec.Command("docker", "inspect", "-f",
+		"{{range .NetworkSettings.Networks}}{{.IPAddress}} {{end}}", ids[0]).Output()
+	if err != nil {
+		return "", fmt.Errorf("error inspecting container %s: %v", containerName, err)
+	}
+
+	addresses := strings.Fields(string(output))
+	if len(addresses) == 0 {
+		return "", fmt.Errorf("container %s has no IP address", containerName)
+	}
+	return addresses[0], nil
+}
@@ -0,0 +1,141 @@
+package host
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"discover/models"
+)
+
+// osReleaseFiles are tried in order; /usr/lib/os-release is the fallback the spec defines
+var osReleaseFiles = []string{"/etc/os-release", "/usr/lib/os-release"}
+
+// GetHostFacts gathers the hostname, OS release, kernel, architecture, uptime, load, memory and
+// the space and inode usage of each mounted filesystem
+func GetHostFacts() models.HostFacts {
+	facts := models.HostFacts{
+		Architecture: architecture(),
+		CPUs:         runtime.NumCPU(),
+		Captured:     time.Now(),
+	}
+
+	if hostname, err := os.Hostname(); err == nil {
+		facts.Hostname = hostname
+	}
+	if release, err := ioutil.ReadFile("/proc/sys/kernel/osrelease"); err == nil {
+		facts.Kernel = strings.TrimSpace(string(release))
+	}
+	readOSRelease(&facts)
+
+	if data, err := ioutil.ReadFile("/proc/uptime"); err == nil {
+		if fields := strings.Fields(string(data)); len(fields) > 0 {
+			seconds, _ := strconv.ParseFloat(fields[0], 64)
+			facts.Uptime = time.Duration(seconds) * time.Second
+		}
+	}
+
+	if data, err := ioutil.ReadFile("/proc/loadavg"); err == nil {
+		if fields := strings.Fields(string(data)); len(fields) >= 3 {
+			facts.Load1, _ = strconv.ParseFloat(fields[0], 64)
+			facts.Load5, _ = strconv.ParseFloat(fields[1], 64)
+			facts.Load15, _ = strconv.ParseFloat(fields[2], 64)
+		}
+	}
+
+	readMemory(&facts)
+
+	filesystems, err := GetFilesystemUsage()
+	if err != nil {
+		fmt.Printf("Warning: Failed to read filesystem usage: %v\n", err)
+	}
+	facts.Filesystems = filesystems
+
+	return facts
+}
+
+// readOSRelease fills in the OS name and version from os-release
+func readOSRelease(facts *models.HostFacts) {
+	for _, file := range osReleaseFiles {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			parts := strings.SplitN(line, "=", 2)
+			if len(parts) != 2 {
+				continue
+			}
+			value := strings.Trim(parts[1], `"'`)
+			switch parts[0] {
+			case "PRETTY_NAME":
+				facts.OS = value
+			case "ID":
+				facts.OSID = value
+			case "VERSION_ID":
+				facts.OSVersion = value
+			}
+		}
+		return
+	}
+	facts.OS = runtime.GOOS
+}
+
+// architecture returns the machine hardware name, e.g. "x86_64", falling back to Go's name for it
+func architecture() string {
+	output, err := exec.Command("uname", "-m").Output()
+	if err != nil {
+		return runtime.GOARCH
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// readMemory fills in memory and swap from /proc/meminfo, whose values are in kB
+func readMemory(facts *models.HostFacts) {
+	data, err := ioutil.ReadFile("/proc/meminfo")
+	if err != nil {
+		return
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		kilobytes, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		switch fields[0] {
+		case "MemTotal:":
+			facts.MemoryTotal = kilobytes * 1024
+		case "MemAvailable:":
+			facts.MemoryAvailable = kilobytes * 1024
+		case "SwapTotal:":
+			facts.SwapTotal = kilobytes * 1024
+		case "SwapFree:":
+			facts.SwapFree = kilobytes * 1024
+		}
+	}
+}
+
+// UsagePercent returns how full a filesystem is, counting the space reserved for root as used
+// like df does
+func UsagePercent(fs models.FilesystemUsage) float64 {
+	if fs.Used+fs.Available == 0 {
+		return 0
+	}
+	return float64(fs.Used) * 100 / float64(fs.Used+fs.Available)
+}
+
+// InodePercent returns the share of a filesystem's inodes in use
+func InodePercent(fs models.FilesystemUsage) float64 {
+	if fs.Inodes == 0 {
+		return 0
+	}
+	return float64(fs.InodesUsed) * 100 / float64(fs.Inodes)
+}
@@ -0,0 +1,68 @@
+package kubernetes
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"discover/sessions"
+)
+
+// shellCommand prefers bash and falls back to sh for minimal images
+const shellCommand = "command -v bash >/dev/null 2>&1 && exec bash || exec sh"
+
+// OpenShell starts an interactive shell in one of a deployment's pods and returns once it exits
+func OpenShell(contextName, deploymentName string) error {
+	namespace, err := findDeploymentNamespace(contextName, deploymentName)
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command("kubectl", "exec", "-it", "deployment/"+deploymentName, "-n", namespace,
+		"--context", contextName, "--", "sh", "-c", shellCommand)
+	if err := sessions.RunInteractive(cmd); err != nil {
+		return fmt.Errorf("shell session for deployment %s in context %s ended with error: %v",
+			deploymentName, contextName, err)
+	}
+	return nil
+}
+
+// PortForward forwards a local port to a port on one of a deployment's pods. In the foreground
+// it returns when the user presses Ctrl+C; in the background it is tracked as a session.
+func PortForward(contextName, deploymentName string, localPort, remotePort int, background bool) error {
+	namespace, err := findDeploymentNamespace(contextName, deploymentName)
+	if err != nil {
+		return err
+	}
+
+	ports := strconv.Itoa(localPort) + ":" + strconv.Itoa(remotePort)
+	cmd := exec.Command("kubectl", "port-forward", "deployment/"+deploymentName, ports, "-n", namespace,
+		"--context", contextName)
+
+	if background {
+		target := fmt.Sprintf("%s/%s/%s", contextName, strings.TrimSpace(namespace), deploymentName)
+		// kubectl reports bad ports, ports in use and pods without ready endpoints right away
+		_, err := sessions.StartBackgroundWhenReady(cmd, "Forwarding from", "kubectl port-forward", target, localPort, remotePort)
+		if err != nil {
+			return fmt.Errorf("error starting port-forward for deployment %s: %v", deploymentName, err)
+		}
+		return nil
+	}
+
+	fmt.Printf("Forwarding localhost:%d to deployment %s port %d, press Ctrl+C to stop\n",
+		localPort, deploymentName, remotePort)
+	if err := sessions.RunInteractive(cmd); err != nil && !isInterrupted(err) {
+		return fmt.Errorf("port-forward for deployment %s ended with error: %v", deploymentName, err)
+	}
+	return nil
+}
+
+// isInterrupted reports whether a command ended because the user pressed Ctrl+C
+func isInterrupted(err error) bool {
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		// Killed by SIGINT, or exited with the conventional 128+SIGINT status
+		return exitErr.ExitCode() == -1 || exitErr.ExitCode() == 130
+	}
+	return false
+}
@@ -0,0 +1,224 @@
+package kubernetes
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/exec"
+	"sort"
+	"time"
+
+	"discover/config"
+	"discover/models"
+)
+
+// Helm stores each release revision as a Secret (the default driver) or a ConfigMap
+// labelled owner=helm, with the release record gzipped and base64 encoded under "release".
+const helmOwnerSelector = "owner=helm"
+
+// helmReleaseRecord is the subset of Helm's release record that discover reports on
+type helmReleaseRecord struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Version   int    `json:"version"`
+	Info      struct {
+		Status       string    `json:"status"`
+		LastDeployed time.Time `json:"last_deployed"`
+	} `json:"info"`
+	Chart struct {
+		Metadata struct {
+			Name       string `json:"name"`
+			Version    string `json:"version"`
+			AppVersion string `json:"appVersion"`
+		} `json:"metadata"`
+	} `json:"chart"`
+}
+
+// GetHelmReleasesForNamespace retrieves the latest revision of every Helm release in a namespace
+func GetHelmReleasesForNamespace(contextName, namespaceName string) ([]models.HelmRelease, error) {
+	releases, err := listHelmReleases(contextName, "-n", namespaceName)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving Helm releases for namespace %s in context %s: %v",
+			namespaceName, contextName, err)
+	}
+	return releases, nil
+}
+
+// GetHelmReleases retrieves the Helm releases across all namespaces in a context,
+// along with the deployments each release owns
+func GetHelmReleases(contextName string) ([]models.HelmRelease, error) {
+	releases, err := listHelmReleases(contextName, "--all-namespaces")
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving Helm releases for context %s: %v", contextName, err)
+	}
+
+	cmd := exec.Command("kubectl", "get", "deployments", "--all-namespaces", "--context", contextName, "-o", "json")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving deployments for Kubernetes context %s: %v", contextName, err)
+	}
+	deployments, err := parseDeployments(output)
+	if err != nil {
+		return nil, err
+	}
+
+	linkHelmReleases(releases, deployments)
+	return releases, nil
+}
+
+// listHelmReleases reads the Helm release records from Secrets, falling back to ConfigMaps
+// for clusters using the configmap storage driver or when Secrets cannot be listed
+func listHelmReleases(contextName string, scope ...string) ([]models.HelmRelease, error) {
+	records, secretsErr := readHelmRecords(contextName, "secrets", scope)
+	if secretsErr != nil || len(records) == 0 {
+		var err error
+		records, err = readHelmRecords(contextName, "configmaps", scope)
+		if err != nil {
+			if secretsErr != nil {
+				return nil, fmt.Errorf("%v; %v", secretsErr, err)
+			}
+			return nil, err
+		}
+		if secretsErr != nil && len(records) == 0 {
+			return nil, secretsErr
+		}
+	}
+
+	// Keep only the newest revision of each release
+	latest := make(map[string]helmReleaseRecord)
+	for _, record := range records {
+		key := record.Namespace + "/" + record.Name
+		if current, exists := latest[key]; !exists || record.Version > current.Version {
+			latest[key] = record
+		}
+	}
+
+	settings := config.Current().Kubernetes
+	releases := []models.HelmRelease{}
+	for _, record := range latest {
+		if !settings.NamespaceAllowed(record.Namespace) {
+			continue
+		}
+		releases = append(releases, models.HelmRelease{
+			Name:         record.Name,
+			Namespace:    record.Namespace,
+			Chart:        record.Chart.Metadata.Name,
+			ChartVersion: record.Chart.Metadata.Version,
+			AppVersion:   record.Chart.Metadata.AppVersion,
+			Status:       record.Info.Status,
+			Revision:     record.Version,
+			LastDeployed: record.Info.LastDeployed,
+		})
+	}
+
+	sort.Slice(releases, func(i, j int) bool {
+		if releases[i].Namespace != releases[j].Namespace {
+			return releases[i].Namespace < releases[j].Namespace
+		}
+		return releases[i].Name < releases[j].Name
+	})
+
+	return releases, nil
+}
+
+// readHelmRecords decodes every Helm release record stored in the given resource kind
+func readHelmRecords(contextName, kind string, scope []string) ([]helmReleaseRecord, error) {
+	args := append([]string{"get", kind, "-l", helmOwnerSelector, "--context", contextName, "-o", "json"}, scope...)
+	cmd := exec.Command("kubectl", args...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("error listing %s: %v", kind, err)
+	}
+
+	// Secret data is base64 encoded by Kubernetes on top of Helm's own encoding,
+	// while ConfigMap data only carries Helm's encoding.
+	var result struct {
+		Items []struct {
+			Data map[string]string `json:"data"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(output, &result); err != nil {
+		return nil, fmt.Errorf("error parsing %s JSON: %v", kind, err)
+	}
+
+	var records []helmReleaseRecord
+	for _, item := range result.Items {
+		payload := item.Data["release"]
+		if kind == "secrets" {
+			decoded, err := base64.StdEncoding.DecodeString(payload)
+			if err != nil {
+				continue
+			}
+			payload = string(decoded)
+		}
+
+		record, err := decodeHelmRecord(payload)
+		if err != nil {
+			fmt.Printf("Warning: Skipping unreadable Helm release record: %v\n", err)
+			continue
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// decodeHelmRecord decodes a base64, optionally gzipped, Helm release record
+func decodeHelmRecord(payload string) (helmReleaseRecord, error) {
+	var record helmReleaseRecord
+
+	data, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		return record, fmt.Errorf("error decoding release: %v", err)
+	}
+
+	// Helm gzips release records, but older releases may be plain JSON
+	if bytes.HasPrefix(data, []byte{0x1f, 0x8b, 0x08}) {
+		reader, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return record, fmt.Errorf("error decompressing release: %v", err)
+		}
+		defer reader.Close()
+
+		data, err = io.ReadAll(reader)
+		if err != nil {
+			return record, fmt.Errorf("error decompressing release: %v", err)
+		}
+	}
+
+	if err := json.Unmarshal(data, &record); err != nil {
+		return record, fmt.Errorf("error parsing release JSON: %v", err)
+	}
+
+	return record, nil
+}
+
+// linkHelmReleases records which deployments belong to each release
+func linkHelmReleases(releases []models.HelmRelease, deployments []models.KubernetesDeployment) {
+	for i := range releases {
+		release := &releases[i]
+		release.Deployments = nil
+		for _, deployment := range deployments {
+			if deployment.HelmRelease != release.Name || deployment.Namespace != release.Namespace {
+				continue
+			}
+			release.Deployments = append(release.Deployments, deployment.Name)
+		}
+		sort.Strings(release.Deployments)
+	}
+}
+
+// helmReleaseName returns the Helm release that owns a resource, based on the
+// ownership annotations Helm 3 sets, or the instance label for older charts
+func helmReleaseName(labels, annotations map[string]string) string {
+	if name := annotations["meta.helm.sh/release-name"]; name != "" {
+		return name
+	}
+	if labels["app.kubernetes.io/managed-by"] == "Helm" {
+		return labels["app.kubernetes.io/instance"]
+	}
+	return ""
+}
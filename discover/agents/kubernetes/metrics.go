@@ -0,0 +1,301 @@
+package kubernetes
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"discover/models"
+)
+
+const metricsAPIPath = "/apis/metrics.k8s.io/v1beta1"
+
+// MemoryLimitWarningPercent is the share of its memory limit a container may use before
+// its workload is flagged as at risk of being OOMKilled
+const MemoryLimitWarningPercent = 90
+
+// podUsage is a pod's container usage along with the labels used to match it to a deployment
+type podUsage struct {
+	namespace  string
+	labels     map[string]string
+	containers []models.KubernetesContainerUsage
+}
+
+// MetricsAvailable reports whether the metrics API (metrics-server) is installed in a context
+func MetricsAvailable(contextName string) bool {
+	cmd := exec.Command("kubectl", "get", "--raw", metricsAPIPath, "--context", contextName)
+	return cmd.Run() == nil
+}
+
+// GetNodeUsage retrieves the CPU and memory usage of every node in a context
+func GetNodeUsage(contextName string) ([]models.KubernetesNodeUsage, error) {
+	cmd := exec.Command("kubectl", "get", "--raw", metricsAPIPath+"/nodes", "--context", contextName)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving node metrics for context %s: %v", contextName, err)
+	}
+
+	var metrics struct {
+		Items []struct {
+			Metadata struct {
+				Name string `json:"name"`
+			} `json:"metadata"`
+			Usage map[string]string `json:"usage"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(output, &metrics); err != nil {
+		return nil, fmt.Errorf("error parsing node metrics JSON: %v", err)
+	}
+
+	// Allocatable capacity comes from the node objects themselves
+	cmd = exec.Command("kubectl", "get", "nodes", "--context", contextName, "-o", "json")
+	output, err = cmd.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving nodes for context %s: %v", contextName, err)
+	}
+
+	var nodes struct {
+		Items []struct {
+			Metadata struct {
+				Name string `json:"name"`
+			} `json:"metadata"`
+			Status struct {
+				Allocatable map[string]string `json:"allocatable"`
+			} `json:"status"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(output, &nodes); err != nil {
+		return nil, fmt.Errorf("error parsing nodes JSON: %v", err)
+	}
+
+	allocatable := make(map[string]map[string]string)
+	for _, node := range nodes.Items {
+		allocatable[node.Metadata.Name] = node.Status.Allocatable
+	}
+
+	var usage []models.KubernetesNodeUsage
+	for _, item := range metrics.Items {
+		capacity := allocatable[item.Metadata.Name]
+		usage = append(usage, models.KubernetesNodeUsage{
+			Name:              item.Metadata.Name,
+			CPUUsage:          parseCPU(item.Usage["cpu"]),
+			CPUAllocatable:    parseCPU(capacity["cpu"]),
+			MemoryUsage:       parseMemory(item.Usage["memory"]),
+			MemoryAllocatable: parseMemory(capacity["memory"]),
+		})
+	}
+
+	return usage, nil
+}
+
+// GetDeploymentUsage retrieves the per-container usage of a deployment's pods
+func GetDeploymentUsage(contextName, deploymentName string) ([]models.KubernetesContainerUsage, error) {
+	namespace, err := findDeploymentNamespace(contextName, deploymentName)
+	if err != nil {
+		return nil, err
+	}
+
+	deployments, err := GetDeploymentsForNamespace(contextName, namespace)
+	if err != nil {
+		return nil, err
+	}
+	pods, err := getPodUsage(contextName, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	linkDeploymentUsage(deployments, pods)
+	for _, deployment := range deployments {
+		if deployment.Name == deploymentName {
+			return deployment.Usage, nil
+		}
+	}
+
+	return nil, fmt.Errorf("could not find deployment %s in namespace %s", deploymentName, namespace)
+}
+
+// AttachDeploymentUsage fills in the usage of deployments from any namespace of a context and
+// flags those with a container near its memory limit. It needs the metrics API.
+func AttachDeploymentUsage(contextName string, deployments []models.KubernetesDeployment) error {
+	pods, err := getPodUsage(contextName, "")
+	if err != nil {
+		return err
+	}
+	linkDeploymentUsage(deployments, pods)
+	return nil
+}
+
+// getPodUsage joins the metrics API pod usage with the requests and limits from the pod specs,
+// for one namespace or for all of them when namespaceName is empty
+func getPodUsage(contextName, namespaceName string) ([]podUsage, error) {
+	metricsPath, scope := metricsAPIPath+"/pods", []string{"--all-namespaces"}
+	if namespaceName != "" {
+		metricsPath, scope = metricsAPIPath+"/namespaces/"+namespaceName+"/pods", []string{"-n", namespaceName}
+	}
+	cmd := exec.Command("kubectl", "get", "--raw", metricsPath, "--context", contextName)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving pod metrics for namespace %s in context %s: %v",
+			namespaceName, contextName, err)
+	}
+
+	var metrics struct {
+		Items []struct {
+			Metadata struct {
+				Name      string `json:"name"`
+				Namespace string `json:"namespace"`
+			} `json:"metadata"`
+			Containers []struct {
+				Name  string            `json:"name"`
+				Usage map[string]string `json:"usage"`
+			} `json:"containers"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(output, &metrics); err != nil {
+		return nil, fmt.Errorf("error parsing pod metrics JSON: %v", err)
+	}
+
+	args := append([]string{"get", "pods", "--context", contextName, "-o", "json"}, scope...)
+	cmd = exec.Command("kubectl", args...)
+	output, err = cmd.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving pods for namespace %s in context %s: %v",
+			namespaceName, contextName, err)
+	}
+
+	type resources struct {
+		Requests map[string]string `json:"requests"`
+		Limits   map[string]string `json:"limits"`
+	}
+	var pods struct {
+		Items []struct {
+			Metadata struct {
+				Name      string            `json:"name"`
+				Namespace string            `json:"namespace"`
+				Labels    map[string]string `json:"labels"`
+			} `json:"metadata"`
+			Spec struct {
+				Containers []struct {
+					Name      string    `json:"name"`
+					Resources resources `json:"resources"`
+				} `json:"containers"`
+			} `json:"spec"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(output, &pods); err != nil {
+		return nil, fmt.Errorf("error parsing pods JSON: %v", err)
+	}
+
+	// Index the container specs by namespace, pod and container name
+	specs := make(map[string]resources)
+	labels := make(map[string]map[string]string)
+	for _, pod := range pods.Items {
+		podKey := pod.Metadata.Namespace + "/" + pod.Metadata.Name
+		labels[podKey] = pod.Metadata.Labels
+		for _, container := range pod.Spec.Containers {
+			specs[podKey+"/"+container.Name] = container.Resources
+		}
+	}
+
+	var usage []podUsage
+	for _, pod := range metrics.Items {
+		podKey := pod.Metadata.Namespace + "/" + pod.Metadata.Name
+		entry := podUsage{
+			namespace: pod.Metadata.Namespace,
+			labels:    labels[podKey],
+		}
+		for _, container := range pod.Containers {
+			spec := specs[podKey+"/"+container.Name]
+			entry.containers = append(entry.containers, models.KubernetesContainerUsage{
+				Pod:           pod.Metadata.Name,
+				Container:     container.Name,
+				CPUUsage:      parseCPU(container.Usage["cpu"]),
+				CPURequest:    parseCPU(spec.Requests["cpu"]),
+				CPULimit:      parseCPU(spec.Limits["cpu"]),
+				MemoryUsage:   parseMemory(container.Usage["memory"]),
+				MemoryRequest: parseMemory(spec.Requests["memory"]),
+				MemoryLimit:   parseMemory(spec.Limits["memory"]),
+			})
+		}
+		usage = append(usage, entry)
+	}
+
+	return usage, nil
+}
+
+// linkDeploymentUsage attaches each pod's usage to the deployment whose template labels it carries
+// and flags deployments with a container near its memory limit
+func linkDeploymentUsage(deployments []models.KubernetesDeployment, pods []podUsage) {
+	for i := range deployments {
+		deployment := &deployments[i]
+		deployment.Usage = nil
+		deployment.NearMemoryLimit = false
+
+		for _, pod := range pods {
+			if pod.namespace != deployment.Namespace || !selectorMatches(deployment.PodLabels, pod.labels) {
+				continue
+			}
+			for _, container := range pod.containers {
+				deployment.Usage = append(deployment.Usage, container)
+				if NearMemoryLimit(container) {
+					deployment.NearMemoryLimit = true
+				}
+			}
+		}
+	}
+}
+
+// NearMemoryLimit reports whether a container uses at least MemoryLimitWarningPercent of its memory limit
+func NearMemoryLimit(usage models.KubernetesContainerUsage) bool {
+	if usage.MemoryLimit == 0 {
+		return false
+	}
+	return usage.MemoryUsage*100 >= usage.MemoryLimit*MemoryLimitWarningPercent
+}
+
+// parseCPU converts a Kubernetes CPU quantity such as "250m", "2" or "15003721n" to millicores
+func parseCPU(quantity string) int64 {
+	return int64(math.Round(parseQuantity(quantity) * 1000))
+}
+
+// parseMemory converts a Kubernetes memory quantity such as "128Mi", "1G" or "52428800" to bytes
+func parseMemory(quantity string) int64 {
+	return int64(math.Round(parseQuantity(quantity)))
+}
+
+// parseQuantity converts a Kubernetes resource quantity to its value in base units,
+// returning 0 for empty or unparseable quantities
+func parseQuantity(quantity string) float64 {
+	quantity = strings.TrimSpace(quantity)
+	if quantity == "" {
+		return 0
+	}
+
+	suffixes := []struct {
+		suffix     string
+		multiplier float64
+	}{
+		{"Ki", 1 << 10}, {"Mi", 1 << 20}, {"Gi", 1 << 30}, {"Ti", 1 << 40}, {"Pi", 1 << 50}, {"Ei", 1 << 60},
+		{"n", 1e-9}, {"u", 1e-6}, {"m", 1e-3},
+		{"k", 1e3}, {"M", 1e6}, {"G", 1e9}, {"T", 1e12}, {"P", 1e15}, {"E", 1e18},
+	}
+	for _, s := range suffixes {
+		if strings.HasSuffix(quantity, s.suffix) {
+			value, err := strconv.ParseFloat(strings.TrimSuffix(quantity, s.suffix), 64)
+			if err != nil {
+				return 0
+			}
+			return value * s.multiplier
+		}
+	}
+
+	// Plain numbers, including exponent forms such as "1e3"
+	value, err := strconv.ParseFloat(quantity, 64)
+	if err != nil {
+		return 0
+	}
+	return value
+}
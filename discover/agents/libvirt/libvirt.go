@@ -0,0 +1,199 @@
+package libvirt
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"discover/config"
+	"discover/models"
+)
+
+// Actions that can be run on a domain
+const (
+	ActionStart    = "start"
+	ActionShutdown = "shutdown"
+	ActionReboot   = "reboot"
+)
+
+// VirshCommand is the virsh binary that is run. Point it at a script printing canned output to
+// exercise the agent without libvirt.
+var VirshCommand = "virsh"
+
+// qemuLogDir holds the QEMU log of each domain, used when a domain does not log its console
+const qemuLogDir = "/var/log/libvirt/qemu"
+
+// consoleLogLines is how many lines of a console log are shown
+const consoleLogLines = 100
+
+// virsh runs a virsh command against the configured connection
+func virsh(args ...string) ([]byte, error) {
+	if uri := config.Current().Libvirt.URI; uri != "" {
+		args = append([]string{"--connect", uri}, args...)
+	}
+
+	output, err := exec.Command(VirshCommand, args...).CombinedOutput()
+	if err != nil {
+		return output, fmt.Errorf("virsh %s failed: %v\nOutput: %s", strings.Join(args, " "), err, strings.TrimSpace(string(output)))
+	}
+	return output, nil
+}
+
+// GetVirtualMachines returns every domain, running or not, with its state, vCPUs, memory and
+// autostart setting
+func GetVirtualMachines() ([]models.VirtualMachine, error) {
+	output, err := virsh("list", "--all", "--name")
+	if err != nil {
+		return nil, fmt.Errorf("error listing libvirt domains: %v", err)
+	}
+
+	var machines []models.VirtualMachine
+	for _, name := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		machine, err := GetVirtualMachine(name)
+		if err != nil {
+			fmt.Printf("Warning: %v\n", err)
+			machine = models.VirtualMachine{Name: name, State: "unknown"}
+		}
+		machines = append(machines, machine)
+	}
+	return machines, nil
+}
+
+// GetVirtualMachine returns a domain as described by `virsh dominfo`
+func GetVirtualMachine(name string) (models.VirtualMachine, error) {
+	output, err := virsh("dominfo", name)
+	if err != nil {
+		return models.VirtualMachine{}, fmt.Errorf("error reading domain %s: %v", name, err)
+	}
+	return parseDominfo(string(output)), nil
+}
+
+// parseDominfo parses `virsh dominfo` lines such as "Max memory:     2097152 KiB"
+func parseDominfo(output string) models.VirtualMachine {
+	var machine models.VirtualMachine
+	for _, line := range strings.Split(output, "\n") {
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		value := strings.TrimSpace(parts[1])
+		switch strings.TrimSpace(parts[0]) {
+		case "Id":
+			if value != "-" {
+				machine.ID = value
+			}
+		case "Name":
+			machine.Name = value
+		case "UUID":
+			machine.UUID = value
+		case "State":
+			machine.State = value
+		case "CPU(s)":
+			machine.VCPUs, _ = strconv.Atoi(value)
+		case "Max memory":
+			machine.MaxMemory = parseKiB(value)
+		case "Used memory":
+			machine.UsedMemory = parseKiB(value)
+		case "Persistent":
+			machine.Persistent = value == "yes"
+		case "Autostart":
+			machine.Autostart = value == "enable"
+		}
+	}
+	return machine
+}
+
+// parseKiB parses a memory size such as "2097152 KiB" into bytes
+func parseKiB(value string) uint64 {
+	fields := strings.Fields(value)
+	if len(fields) == 0 {
+		return 0
+	}
+	kibibytes, _ := strconv.ParseUint(fields[0], 10, 64)
+	return kibibytes * 1024
+}
+
+// ControlVirtualMachine starts a domain, or asks its guest to shut down or reboot. Shutdown and
+// reboot return once the request is sent; the guest may take a while or ignore it.
+func ControlVirtualMachine(name, action string) error {
+	switch action {
+	case ActionStart, ActionShutdown, ActionReboot:
+	default:
+		return fmt.Errorf("unsupported virtual machine action %q", action)
+	}
+
+	if _, err := virsh(action, name); err != nil {
+		return fmt.Errorf("error running %s on domain %s: %v", action, name, err)
+	}
+	return nil
+}
+
+// domainXML holds the parts of a domain definition that say where its console is logged
+type domainXML struct {
+	Devices struct {
+		Serials  []characterDevice `xml:"serial"`
+		Consoles []characterDevice `xml:"console"`
+	} `xml:"devices"`
+}
+
+// characterDevice is a serial port or console of a domain
+type characterDevice struct {
+	Type   string `xml:"type,attr"`
+	Source struct {
+		Path string `xml:"path,attr"`
+	} `xml:"source"`
+	Log struct {
+		File string `xml:"file,attr"`
+	} `xml:"log"`
+}
+
+// ConsoleLogPath returns the file the guest console is written to: the <log> of a serial port or
+// console, or the path of one backed by a file. Without either the QEMU log of the domain is used.
+func ConsoleLogPath(name string) (string, error) {
+	output, err := virsh("dumpxml", name)
+	if err != nil {
+		return "", fmt.Errorf("error reading definition of domain %s: %v", name, err)
+	}
+
+	var domain domainXML
+	if err := xml.Unmarshal(output, &domain); err != nil {
+		return "", fmt.Errorf("error parsing definition of domain %s: %v", name, err)
+	}
+
+	for _, device := range append(domain.Devices.Serials, domain.Devices.Consoles...) {
+		if device.Log.File != "" {
+			return device.Log.File, nil
+		}
+		if device.Type == "file" && device.Source.Path != "" {
+			return device.Source.Path, nil
+		}
+	}
+	return filepath.Join(qemuLogDir, name+".log"), nil
+}
+
+// GetConsoleLog returns the last lines of a domain's console log
+func GetConsoleLog(name string) string {
+	path, err := ConsoleLogPath(name)
+	if err != nil {
+		return err.Error()
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Sprintf("Error reading console log %s: %v", path, err)
+	}
+
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	if len(lines) > consoleLogLines {
+		lines = lines[len(lines)-consoleLogLines:]
+	}
+	return fmt.Sprintf("==> %s <==\n%s", path, strings.Join(lines, "\n"))
+}
@@ -0,0 +1,185 @@
+package libvirt
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"discover/config"
+	"discover/models"
+)
+
+// fakeVirsh points VirshCommand at a script answering like virsh and returns the file the script
+// appends its arguments to
+func fakeVirsh(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	calls := filepath.Join(dir, "calls")
+	script := `#!/bin/sh
+echo "$*" >> ` + calls + `
+[ "$1" = --connect ] && shift 2
+case "$1 $2" in
+"list "*) printf 'web01\ndb01\n\n';;
+"dominfo web01") cat <<EOF
+Id:             3
+Name:           web01
+UUID:           6c3b0a5e-8f1d-4d3a-9b1e-2f0c7d9e4a11
+OS Type:        hvm
+State:          running
+CPU(s):         2
+CPU time:       12.3s
+Max memory:     2097152 KiB
+Used memory:    1048576 KiB
+Persistent:     yes
+Autostart:      enable
+Managed save:   no
+EOF
+;;
+"dominfo db01") cat <<EOF
+Id:             -
+Name:           db01
+UUID:           0d4e8f2a-1b3c-4e5f-8a9b-7c6d5e4f3a21
+State:          shut off
+CPU(s):         4
+Max memory:     4194304 KiB
+Used memory:    4194304 KiB
+Persistent:     no
+Autostart:      disable
+EOF
+;;
+"dumpxml logged") echo "<domain><devices>
+  <serial type='pty'><source path='/dev/pts/3'/><log file='/var/log/libvirt/qemu/logged-serial0.log' append='off'/></serial>
+  <console type='pty'><source path='/dev/pts/3'/></console>
+</devices></domain>";;
+"dumpxml filebacked") echo "<domain><devices>
+  <serial type='pty'><source path='/dev/pts/4'/></serial>
+  <console type='file'><source path='/var/lib/libvirt/console/filebacked.log'/></console>
+</devices></domain>";;
+"dumpxml pty") echo "<domain><devices><serial type='pty'><source path='/dev/pts/5'/></serial></devices></domain>";;
+"start db01") echo "Domain 'db01' started";;
+*) echo "error: failed to get domain '$2'" >&2; exit 1;;
+esac
+`
+	path := filepath.Join(dir, "virsh")
+	if err := ioutil.WriteFile(path, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	previous := VirshCommand
+	VirshCommand = path
+	t.Cleanup(func() { VirshCommand = previous })
+	config.Set(config.Config{})
+	return calls
+}
+
+// virshCalls returns the argument lists virsh was run with
+func virshCalls(t *testing.T, calls string) []string {
+	t.Helper()
+	data, err := ioutil.ReadFile(calls)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Split(strings.TrimSpace(string(data)), "\n")
+}
+
+func TestGetVirtualMachines(t *testing.T) {
+	fakeVirsh(t)
+
+	machines, err := GetVirtualMachines()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []models.VirtualMachine{
+		{
+			Name: "web01", ID: "3", UUID: "6c3b0a5e-8f1d-4d3a-9b1e-2f0c7d9e4a11", State: "running",
+			VCPUs: 2, MaxMemory: 2097152 * 1024, UsedMemory: 1048576 * 1024, Persistent: true, Autostart: true,
+		},
+		{
+			Name: "db01", UUID: "0d4e8f2a-1b3c-4e5f-8a9b-7c6d5e4f3a21", State: "shut off",
+			VCPUs: 4, MaxMemory: 4194304 * 1024, UsedMemory: 4194304 * 1024,
+		},
+	}
+	if !reflect.DeepEqual(machines, want) {
+		t.Errorf("got %+v, want %+v", machines, want)
+	}
+}
+
+func TestParseDominfo(t *testing.T) {
+	machine := parseDominfo("Id:             -\nName:           empty\nState:          crashed\nCPU(s):         x\nMax memory:\n")
+	want := models.VirtualMachine{Name: "empty", State: "crashed"}
+	if !reflect.DeepEqual(machine, want) {
+		t.Errorf("got %+v, want %+v", machine, want)
+	}
+}
+
+func TestConnectURI(t *testing.T) {
+	calls := fakeVirsh(t)
+	config.Set(config.Config{Libvirt: config.LibvirtSettings{URI: "qemu:///session"}})
+
+	if _, err := GetVirtualMachine("web01"); err != nil {
+		t.Fatal(err)
+	}
+	if got := virshCalls(t, calls); !reflect.DeepEqual(got, []string{"--connect qemu:///session dominfo web01"}) {
+		t.Errorf("virsh was run with %q", got)
+	}
+}
+
+func TestConsoleLogPath(t *testing.T) {
+	fakeVirsh(t)
+
+	tests := []struct {
+		domain string
+		want   string
+	}{
+		// The <log> of the serial port wins over the pty console
+		{"logged", "/var/log/libvirt/qemu/logged-serial0.log"},
+		// A console backed by a file is read directly
+		{"filebacked", "/var/lib/libvirt/console/filebacked.log"},
+		// Without either, the QEMU log of the domain
+		{"pty", filepath.Join(qemuLogDir, "pty.log")},
+	}
+	for _, test := range tests {
+		path, err := ConsoleLogPath(test.domain)
+		if err != nil {
+			t.Errorf("%s: %v", test.domain, err)
+			continue
+		}
+		if path != test.want {
+			t.Errorf("%s: got %s, want %s", test.domain, path, test.want)
+		}
+	}
+
+	if _, err := ConsoleLogPath("missing"); err == nil {
+		t.Error("expected an error for an unknown domain")
+	}
+}
+
+func TestControlVirtualMachine(t *testing.T) {
+	calls := fakeVirsh(t)
+
+	for _, action := range []string{"destroy", "undefine", "", "start; reboot"} {
+		if err := ControlVirtualMachine("db01", action); err == nil {
+			t.Errorf("expected action %q to be rejected", action)
+		}
+	}
+	if got := virshCalls(t, calls); got != nil {
+		t.Fatalf("virsh was run for rejected actions: %q", got)
+	}
+
+	if err := ControlVirtualMachine("db01", ActionStart); err != nil {
+		t.Error(err)
+	}
+	if err := ControlVirtualMachine("missing", ActionReboot); err == nil {
+		t.Error("expected virsh failing to be reported")
+	}
+	want := []string{"start db01", "reboot missing"}
+	if got := virshCalls(t, calls); !reflect.DeepEqual(got, want) {
+		t.Errorf("virsh was run with %q, want %q", got, want)
+	}
+}
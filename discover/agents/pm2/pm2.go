@@ -0,0 +1,148 @@
+package pm2
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"discover/models"
+)
+
+const (
+	// Manager identifies PM2 apps in ManagedProgram.Manager
+	Manager = "pm2"
+	// logLines is how many lines of an app log are shown
+	logLines = 100
+	// logTailBytes is how much of the end of an app log is read to find them
+	logTailBytes = 16384
+)
+
+// jlistProcess holds the fields of `pm2 jlist` that we use
+type jlistProcess struct {
+	Name  string `json:"name"`
+	PMID  int    `json:"pm_id"`
+	PID   int    `json:"pid"`
+	Monit struct {
+		Memory uint64  `json:"memory"`
+		CPU    float64 `json:"cpu"`
+	} `json:"monit"`
+	Env struct {
+		Status      string `json:"status"`
+		Uptime      int64  `json:"pm_uptime"`
+		RestartTime int    `json:"restart_time"`
+		OutLogPath  string `json:"pm_out_log_path"`
+		ErrLogPath  string `json:"pm_err_log_path"`
+	} `json:"pm2_env"`
+}
+
+// GetPM2Apps returns the apps of the current user's PM2 daemon with their state, PID, uptime,
+// restart count, CPU, memory and log files
+func GetPM2Apps() ([]models.ManagedProgram, error) {
+	output, err := exec.Command("pm2", "jlist").Output()
+	if err != nil {
+		return nil, fmt.Errorf("error listing PM2 apps: %v", err)
+	}
+	return parseJlist(output)
+}
+
+// parseJlist parses `pm2 jlist`. PM2 may print notices such as "[PM2] Spawning PM2 daemon" before
+// the JSON, which is the last line.
+func parseJlist(output []byte) ([]models.ManagedProgram, error) {
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	list := lines[len(lines)-1]
+	if !strings.HasPrefix(list, "[") {
+		return nil, fmt.Errorf("error parsing PM2 app list: no JSON found")
+	}
+
+	var processes []jlistProcess
+	if err := json.Unmarshal([]byte(list), &processes); err != nil {
+		return nil, fmt.Errorf("error parsing PM2 app list: %v", err)
+	}
+
+	apps := make([]models.ManagedProgram, 0, len(processes))
+	for _, process := range processes {
+		app := models.ManagedProgram{
+			Manager:     Manager,
+			Name:        process.Name,
+			ID:          strconv.Itoa(process.PMID),
+			State:       process.Env.Status,
+			PID:         process.PID,
+			Restarts:    process.Env.RestartTime,
+			CPUPercent:  process.Monit.CPU,
+			MemoryBytes: process.Monit.Memory,
+			OutLog:      process.Env.OutLogPath,
+			ErrLog:      process.Env.ErrLogPath,
+		}
+		// pm_uptime is when the app last started, in milliseconds
+		if process.Env.Status == "online" && process.Env.Uptime > 0 {
+			app.Uptime = time.Since(time.Unix(0, process.Env.Uptime*int64(time.Millisecond))).Round(time.Second)
+		}
+		apps = append(apps, app)
+	}
+	return apps, nil
+}
+
+// GetAppLogs returns the last lines of an app's stdout log, or its stderr log when stderr is true
+func GetAppLogs(app models.ManagedProgram, stderr bool) string {
+	path := app.OutLog
+	if stderr {
+		path = app.ErrLog
+	}
+	if path == "" || path == "/dev/null" {
+		return fmt.Sprintf("App %s does not write this log to a file", app.Name)
+	}
+
+	data, truncated, err := readTail(path, logTailBytes)
+	if err != nil {
+		return fmt.Sprintf("Error reading log %s: %v", path, err)
+	}
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	if truncated && len(lines) > 1 {
+		// The first line was cut where the read started
+		lines = lines[1:]
+	}
+	if len(lines) > logLines {
+		lines = lines[len(lines)-logLines:]
+	}
+	return fmt.Sprintf("==> %s <==\n%s", path, strings.Join(lines, "\n"))
+}
+
+// readTail reads at most maxBytes from the end of a file, since logs without pm2-logrotate grow
+// without bound. It reports whether the start of the file was left out.
+func readTail(path string, maxBytes int64) ([]byte, bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, false, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, false, err
+	}
+	offset := info.Size() - maxBytes
+	if offset < 0 {
+		offset = 0
+	}
+
+	data := make([]byte, info.Size()-offset)
+	n, err := file.ReadAt(data, offset)
+	if err != nil && err != io.EOF {
+		return nil, false, err
+	}
+	return data[:n], offset > 0, nil
+}
+
+// RestartApp restarts a PM2 app by its ID
+func RestartApp(app models.ManagedProgram) error {
+	output, err := exec.Command("pm2", "restart", app.ID).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("error restarting PM2 app %s: %v\nOutput: %s", app.Name, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+package ports
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"discover/agents/cgroup"
+	"discover/models"
+)
+
+// tcpListen is the state of a listening socket in /proc/net/tcp
+const tcpListen = "0A"
+
+// GetListeningPorts returns the listening TCP sockets with the process, user and resource that
+// own them, ordered by port. Sockets in the network namespaces of containers and pods are
+// included too. Processes and namespaces of other users are only found when running as root.
+func GetListeningPorts() ([]models.ListeningSocket, error) {
+	var sockets []models.ListeningSocket
+	seen := make(map[uint64]bool)
+	for _, namespace := range networkNamespaces() {
+		for _, protocol := range []string{"tcp", "tcp6"} {
+			path := filepath.Join(namespace.procDir, "net", protocol)
+			found, err := readSockets(path, protocol)
+			if err != nil {
+				if os.IsNotExist(err) {
+					// IPv6 may be disabled, or the process exited
+					continue
+				}
+				if namespace.host {
+					return nil, fmt.Errorf("error reading %s: %v", path, err)
+				}
+				continue
+			}
+			for _, socket := range found {
+				if seen[socket.Inode] {
+					continue
+				}
+				seen[socket.Inode] = true
+				socket.OtherNamespace = !namespace.host
+				sockets = append(sockets, socket)
+			}
+		}
+	}
+
+	processes := socketProcesses()
+	resolver := cgroup.NewResolver()
+	for i := range sockets {
+		pid, ok := processes[sockets[i].Inode]
+		if !ok {
+			continue
+		}
+		sockets[i].PID = pid
+		sockets[i].Process = processName(pid)
+		sockets[i].Owner = resolver.OwnerOfPID(pid)
+	}
+
+	sort.Slice(sockets, func(i, j int) bool {
+		if sockets[i].Port != sockets[j].Port {
+			return sockets[i].Port < sockets[j].Port
+		}
+		return sockets[i].Protocol < sockets[j].Protocol
+	})
+	return sockets, nil
+}
+
+// FindPort returns the listening sockets on a port
+func FindPort(sockets []models.ListeningSocket, port int) []models.ListeningSocket {
+	var matching []models.ListeningSocket
+	for _, socket := range sockets {
+		if socket.Port == port {
+			matching = append(matching, socket)
+		}
+	}
+	return matching
+}
+
+// networkNamespace is a network namespace along with a /proc directory to read its sockets from
+type networkNamespace struct {
+	procDir string
+	// host is set for the namespace discover runs in
+	host bool
+}
+
+// networkNamespaces returns the network namespace discover runs in, followed by one process for
+// each other network namespace found in /proc/*/ns/net, such as those of containers and pods
+func networkNamespaces() []networkNamespace {
+	namespaces := []networkNamespace{{procDir: "/proc/self", host: true}}
+	seen := make(map[string]bool)
+	if own, err := os.Readlink("/proc/self/ns/net"); err == nil {
+		seen[own] = true
+	}
+
+	links, _ := filepath.Glob("/proc/[0-9]*/ns/net")
+	for _, link := range links {
+		// Links look like "net:[4026531840]", naming the namespace inode
+		target, err := os.Readlink(link)
+		if err != nil || seen[target] {
+			continue
+		}
+		seen[target] = true
+		namespaces = append(namespaces, networkNamespace{procDir: filepath.Dir(filepath.Dir(link))})
+	}
+	return namespaces
+}
+
+// readSockets reads the listening sockets of a net/tcp or net/tcp6 file, whose lines look like
+// "0: 00000000:1F90 00000000:0000 0A 00000000:00000000 00:00000000 00000000 1000 0 12345 ..."
+func readSockets(path, protocol string) ([]models.ListeningSocket, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var sockets []models.ListeningSocket
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	// The first line is a header
+	for _, line := range lines[1:] {
+		fields := strings.Fields(line)
+		if len(fields) < 10 || fields[3] != tcpListen {
+			continue
+		}
+
+		address, port, err := parseAddress(fields[1])
+		if err != nil {
+			continue
+		}
+		inode, _ := strconv.ParseUint(fields[9], 10, 64)
+
+		socket := models.ListeningSocket{
+			Protocol: protocol,
+			Address:  address,
+			Port:     port,
+			Inode:    inode,
+			User:     fields[7],
+		}
+		if account, err := user.LookupId(fields[7]); err == nil {
+			socket.User = account.Username
+		}
+		sockets = append(sockets, socket)
+	}
+	return sockets, nil
+}
+
+// parseAddress decodes a hex "address:port". The address is stored as 32-bit words in host
+// byte order, which is little-endian on the machines we run on, so each word is reversed.
+func parseAddress(text string) (string, int, error) {
+	parts := strings.SplitN(text, ":", 2)
+	if len(parts) != 2 {
+		return "", 0, fmt.Errorf("invalid socket address %q", text)
+	}
+
+	raw, err := hex.DecodeString(parts[0])
+	if err != nil || (len(raw) != net.IPv4len && len(raw) != net.IPv6len) {
+		return "", 0, fmt.Errorf("invalid socket address %q", text)
+	}
+	for word := 0; word < len(raw); word += 4 {
+		raw[word], raw[word+1], raw[word+2], raw[word+3] = raw[word+3], raw[word+2], raw[word+1], raw[word]
+	}
+
+	port, err := strconv.ParseUint(parts[1], 16, 16)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid socket port %q", text)
+	}
+	return net.IP(raw).String(), int(port), nil
+}
+
+// socketProcesses maps socket inodes to the PID of a process holding them open, by reading the
+// "socket:[inode]" links in /proc/*/fd. Directories we are not allowed to read are skipped.
+func socketProcesses() map[uint64]int {
+	processes := make(map[uint64]int)
+
+	links, _ := filepath.Glob("/proc/[0-9]*/fd/*")
+	for _, link := range links {
+		target, err := os.Readlink(link)
+		if err != nil || !strings.HasPrefix(target, "socket:[") {
+			continue
+		}
+		inode, err := strconv.ParseUint(strings.TrimSuffix(strings.TrimPrefix(target, "socket:["), "]"), 10, 64)
+		if err != nil {
+			continue
+		}
+		pid, _ := strconv.Atoi(strings.Split(link, "/")[2])
+
+		// Forked workers share their parent's socket; keep the lowest PID, usually the parent
+		if existing, ok := processes[inode]; !ok || pid < existing {
+			processes[inode] = pid
+		}
+	}
+	return processes
+}
+
+// processName returns the command name of a process
+func processName(pid int) string {
+	data, err := ioutil.ReadFile(fmt.Sprintf("/proc/%d/comm", pid))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
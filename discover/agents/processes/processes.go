@@ -0,0 +1,252 @@
+package processes
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"discover/agents/cgroup"
+	"discover/models"
+)
+
+const (
+	// SampleInterval is how long CPU usage is measured for
+	SampleInterval = 500 * time.Millisecond
+	// RunawayCPUPercent is the CPU usage, in percent of one CPU, above which a process is runaway
+	RunawayCPUPercent = 90
+	// RunawayMemoryPercent is the share of total memory above which a process is runaway
+	RunawayMemoryPercent = 25
+	// clockTicks is USER_HZ, the unit of CPU times in /proc, which is 100 on all common architectures
+	clockTicks = 100
+	// kthreadd is the parent of all kernel threads
+	kthreadd = 2
+)
+
+// procStat holds the fields of /proc/[pid]/stat that we use
+type procStat struct {
+	name      string
+	state     string
+	ppid      int
+	cpuTicks  uint64
+	threads   int
+	startTick uint64
+	rssPages  uint64
+}
+
+// GetProcesses returns the user-space processes with their CPU usage sampled over SampleInterval,
+// their memory, and the unit, container or pod they belong to. Kernel threads are left out.
+func GetProcesses() ([]models.Process, error) {
+	before, err := readStats()
+	if err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	time.Sleep(SampleInterval)
+	after, err := readStats()
+	if err != nil {
+		return nil, err
+	}
+	elapsed := time.Since(start).Seconds()
+
+	bootTime := readBootTime()
+	totalMemory := readTotalMemory()
+	pageSize := uint64(os.Getpagesize())
+	resolver := cgroup.NewResolver()
+	users := make(map[string]string)
+
+	var processes []models.Process
+	for pid, stat := range after {
+		if pid == kthreadd || stat.ppid == kthreadd {
+			continue
+		}
+
+		process := models.Process{
+			PID:         pid,
+			PPID:        stat.ppid,
+			Name:        stat.name,
+			Command:     readCommand(pid),
+			User:        processUser(pid, users),
+			State:       stat.state,
+			MemoryBytes: stat.rssPages * pageSize,
+			Threads:     stat.threads,
+			Zombie:      stat.state == "Z",
+			Owner:       resolver.OwnerOfPID(pid),
+		}
+		if !bootTime.IsZero() {
+			process.Started = bootTime.Add(time.Duration(stat.startTick) * time.Second / clockTicks)
+		}
+		if previous, ok := before[pid]; ok && previous.startTick == stat.startTick && elapsed > 0 {
+			process.CPUPercent = float64(stat.cpuTicks-previous.cpuTicks) / clockTicks / elapsed * 100
+		}
+		process.Runaway = process.CPUPercent > RunawayCPUPercent ||
+			(totalMemory > 0 && process.MemoryBytes*100/totalMemory > RunawayMemoryPercent)
+
+		processes = append(processes, process)
+	}
+
+	sort.Slice(processes, func(i, j int) bool { return processes[i].PID < processes[j].PID })
+	return processes, nil
+}
+
+// GroupByOwner groups processes by the unit, container or pod they belong to, busiest first.
+// Processes outside any known resource are grouped by their cgroup.
+func GroupByOwner(processes []models.Process) []models.ProcessGroup {
+	var groups []models.ProcessGroup
+	index := make(map[string]int)
+	for _, process := range processes {
+		key := process.Owner.Kind + "|" + process.Owner.Scope + "|" + process.Owner.User + "|" + process.Owner.Name
+		if process.Owner.Kind == "" {
+			key = "|" + process.Owner.Cgroup
+		}
+
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, models.ProcessGroup{Owner: process.Owner})
+		}
+		groups[i].Processes = append(groups[i].Processes, process)
+		groups[i].CPUPercent += process.CPUPercent
+		groups[i].MemoryBytes += process.MemoryBytes
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		if groups[i].CPUPercent != groups[j].CPUPercent {
+			return groups[i].CPUPercent > groups[j].CPUPercent
+		}
+		return groups[i].MemoryBytes > groups[j].MemoryBytes
+	})
+	return groups
+}
+
+// Children maps each PID to the PIDs of its children, in PID order
+func Children(processes []models.Process) map[int][]int {
+	children := make(map[int][]int)
+	for _, process := range processes {
+		children[process.PPID] = append(children[process.PPID], process.PID)
+	}
+	return children
+}
+
+// readStats reads /proc/[pid]/stat of every process. Processes that exit while we read are skipped.
+func readStats() (map[int]procStat, error) {
+	dirs, err := filepath.Glob("/proc/[0-9]*")
+	if err != nil || len(dirs) == 0 {
+		return nil, fmt.Errorf("error listing processes in /proc: %v", err)
+	}
+
+	stats := make(map[int]procStat)
+	for _, dir := range dirs {
+		pid, err := strconv.Atoi(filepath.Base(dir))
+		if err != nil {
+			continue
+		}
+		data, err := ioutil.ReadFile(filepath.Join(dir, "stat"))
+		if err != nil {
+			continue
+		}
+		if stat, ok := parseStat(string(data)); ok {
+			stats[pid] = stat
+		}
+	}
+	return stats, nil
+}
+
+// parseStat parses /proc/[pid]/stat: "pid (comm) state ppid ...". The command name may itself
+// contain spaces and parentheses, so the fields start after the last ")".
+func parseStat(data string) (procStat, bool) {
+	open := strings.Index(data, "(")
+	end := strings.LastIndex(data, ")")
+	if open == -1 || end < open {
+		return procStat{}, false
+	}
+
+	fields := strings.Fields(data[end+1:])
+	// Fields are numbered from state (field 3 in proc(5)); rss is field 24
+	if len(fields) < 22 {
+		return procStat{}, false
+	}
+
+	stat := procStat{name: data[open+1 : end], state: fields[0]}
+	stat.ppid, _ = strconv.Atoi(fields[1])
+	utime, _ := strconv.ParseUint(fields[11], 10, 64)
+	stime, _ := strconv.ParseUint(fields[12], 10, 64)
+	stat.cpuTicks = utime + stime
+	stat.threads, _ = strconv.Atoi(fields[17])
+	stat.startTick, _ = strconv.ParseUint(fields[19], 10, 64)
+	stat.rssPages, _ = strconv.ParseUint(fields[21], 10, 64)
+	return stat, true
+}
+
+// readCommand returns the command line of a process
+func readCommand(pid int) string {
+	data, err := ioutil.ReadFile(fmt.Sprintf("/proc/%d/cmdline", pid))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(strings.ReplaceAll(string(data), "\x00", " "))
+}
+
+// processUser returns the name of the real user of a process, caching lookups by UID
+func processUser(pid int, users map[string]string) string {
+	data, err := ioutil.ReadFile(fmt.Sprintf("/proc/%d/status", pid))
+	if err != nil {
+		return ""
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || fields[0] != "Uid:" {
+			continue
+		}
+		uid := fields[1]
+		if name, ok := users[uid]; ok {
+			return name
+		}
+		users[uid] = uid
+		if account, err := user.LookupId(uid); err == nil {
+			users[uid] = account.Username
+		}
+		return users[uid]
+	}
+	return ""
+}
+
+// readBootTime reads the boot time from the btime line of /proc/stat
+func readBootTime() time.Time {
+	data, err := ioutil.ReadFile("/proc/stat")
+	if err != nil {
+		return time.Time{}
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.HasPrefix(line, "btime ") {
+			seconds, err := strconv.ParseInt(strings.TrimSpace(strings.TrimPrefix(line, "btime ")), 10, 64)
+			if err == nil {
+				return time.Unix(seconds, 0)
+			}
+		}
+	}
+	return time.Time{}
+}
+
+// readTotalMemory reads MemTotal from /proc/meminfo in bytes
+func readTotalMemory() uint64 {
+	data, err := ioutil.ReadFile("/proc/meminfo")
+	if err != nil {
+		return 0
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && fields[0] == "MemTotal:" {
+			kilobytes, _ := strconv.ParseUint(fields[1], 10, 64)
+			return kilobytes * 1024
+		}
+	}
+	return 0
+}
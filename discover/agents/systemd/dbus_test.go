@@ -0,0 +1,258 @@
+package systemd
+
+import (
+	"bufio"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/godbus/dbus/v5/prop"
+)
+
+// fakeManager stands in for org.freedesktop.systemd1.Manager
+type fakeManager struct {
+	units []dbusUnit
+}
+
+func (m *fakeManager) ListUnits() ([]dbusUnit, *dbus.Error) {
+	return m.units, nil
+}
+
+func (m *fakeManager) LoadUnit(name string) (dbus.ObjectPath, *dbus.Error) {
+	for _, unit := range m.units {
+		if unit.Name == name {
+			return unit.Path, nil
+		}
+	}
+	return "", dbus.NewError("org.freedesktop.systemd1.NoSuchUnit", []interface{}{"Unit " + name + " not found."})
+}
+
+// startPrivateBus runs a bus daemon for the test and returns its address
+func startPrivateBus(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("dbus-daemon"); err != nil {
+		t.Skip("dbus-daemon is not installed")
+	}
+
+	cmd := exec.Command("dbus-daemon", "--session", "--nofork", "--print-address")
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Skipf("cannot start dbus-daemon: %v", err)
+	}
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	})
+
+	address, err := bufio.NewReader(stdout).ReadString('\n')
+	if err != nil {
+		t.Fatalf("reading the bus address: %v", err)
+	}
+	return strings.TrimSpace(address)
+}
+
+// exportFakeSystemd connects to the bus at address and exports a manager with the given units.
+// Each unit gets the properties in unitProperties under the Unit interface and serviceProperties
+// under the Service interface.
+func exportFakeSystemd(t *testing.T, address string, units []dbusUnit,
+	unitProperties, serviceProperties map[string]interface{}) {
+	t.Helper()
+
+	conn, err := dbus.Connect(address)
+	if err != nil {
+		t.Fatalf("connecting the fake manager: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	if err := conn.Export(&fakeManager{units: units}, systemdObjectPath, managerInterface); err != nil {
+		t.Fatal(err)
+	}
+	for _, unit := range units {
+		properties := map[string]map[string]*prop.Prop{
+			unitInterface:                  propsOf(unitProperties),
+			unitInterfacePrefix + "Service": propsOf(serviceProperties),
+		}
+		if _, err := prop.Export(conn, unit.Path, properties); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	reply, err := conn.RequestName(systemdBusName, dbus.NameFlagDoNotQueue)
+	if err != nil || reply != dbus.RequestNameReplyPrimaryOwner {
+		t.Fatalf("requesting %s: %v (reply %d)", systemdBusName, err, reply)
+	}
+}
+
+func propsOf(values map[string]interface{}) map[string]*prop.Prop {
+	props := make(map[string]*prop.Prop)
+	for name, value := range values {
+		props[name] = &prop.Prop{Value: value, Emit: prop.EmitFalse}
+	}
+	return props
+}
+
+// useBus points both the system and the user manager connections at the bus at address
+func useBus(t *testing.T, address string) {
+	t.Helper()
+	systemBus, userBus := connectSystemBus, connectUserBus
+	t.Cleanup(func() { connectSystemBus, connectUserBus = systemBus, userBus })
+
+	connect := func() (*dbus.Conn, error) { return dbus.Connect(address) }
+	connectSystemBus, connectUserBus = connect, connect
+}
+
+var fakeUnits = []dbusUnit{
+	{Name: "web.service", Description: "Web", LoadState: "loaded", ActiveState: "active", SubState: "running",
+		Path: "/org/freedesktop/systemd1/unit/web_2eservice", JobPath: "/"},
+	{Name: "db.service", Description: "DB", LoadState: "loaded", ActiveState: "failed", SubState: "failed",
+		Path: "/org/freedesktop/systemd1/unit/db_2eservice", JobPath: "/"},
+	{Name: "web.socket", Description: "Web socket", LoadState: "loaded", ActiveState: "active", SubState: "listening",
+		Path: "/org/freedesktop/systemd1/unit/web_2esocket", JobPath: "/"},
+}
+
+func TestListUnitsDBus(t *testing.T) {
+	address := startPrivateBus(t)
+	exportFakeSystemd(t, address, fakeUnits, nil, nil)
+	useBus(t, address)
+
+	for _, target := range []managerTarget{systemManager, {scope: ScopeUser, current: true}} {
+		units, err := listUnitsDBus(target, ".service")
+		if err != nil {
+			t.Fatalf("%s: %v", target.scope, err)
+		}
+		want := []unitStatus{
+			{Name: "web.service", Description: "Web", LoadState: "loaded", ActiveState: "active", SubState: "running"},
+			{Name: "db.service", Description: "DB", LoadState: "loaded", ActiveState: "failed", SubState: "failed"},
+		}
+		if !reflect.DeepEqual(units, want) {
+			t.Errorf("%s: got %+v, want %+v", target.scope, units, want)
+		}
+	}
+
+	units, err := listUnitsDBus(systemManager, "")
+	if err != nil || len(units) != len(fakeUnits) {
+		t.Errorf("listing all units: got %d units (%v), want %d", len(units), err, len(fakeUnits))
+	}
+}
+
+func TestUnitPropertiesDBus(t *testing.T) {
+	address := startPrivateBus(t)
+	exportFakeSystemd(t, address, fakeUnits,
+		map[string]interface{}{
+			"Id":          "web.service",
+			"ActiveState": "active",
+			"Requires":    []string{"db.service", "network.target"},
+			"CanReload":   true,
+		},
+		map[string]interface{}{
+			"MainPID":       uint32(4242),
+			"MemoryCurrent": uint64(1048576),
+			"ExecStart":     []string{"/usr/bin/web --name \"my site\""},
+		})
+	useBus(t, address)
+
+	properties, err := unitPropertiesDBus(systemManager, "web.service")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"Id":            "web.service",
+		"ActiveState":   "active",
+		"Requires":      "db.service network.target",
+		"CanReload":     "yes",
+		"MainPID":       "4242",
+		"MemoryCurrent": "1048576",
+		"ExecStart":     `"/usr/bin/web --name \"my site\""`,
+	}
+	if !reflect.DeepEqual(properties, want) {
+		t.Errorf("got %v, want %v", properties, want)
+	}
+
+	if _, err := unitPropertiesDBus(systemManager, "missing.service"); err == nil {
+		t.Error("expected an error for a unit the manager does not know")
+	}
+}
+
+func TestFormatVariant(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		want  string
+	}{
+		{"running", "running"},
+		{true, "yes"},
+		{false, "no"},
+		{uint64(42), "42"},
+		{int32(-1), "-1"},
+		{dbus.ObjectPath("/org/freedesktop/systemd1/unit/web_2eservice"), "/org/freedesktop/systemd1/unit/web_2eservice"},
+		{[]string{"a.service", "b.service"}, "a.service b.service"},
+		{[]string{"plain", "with space", `with "quote"`}, `plain "with space" "with \"quote\""`},
+		{[]string{}, ""},
+		{[][]interface{}{{"Stream", "0.0.0.0:80"}, {"Datagram", "/run/web.sock"}}, "0.0.0.0:80 (Stream)\n/run/web.sock (Datagram)"},
+	}
+
+	for _, test := range tests {
+		if got := formatVariant(dbus.MakeVariant(test.value)); got != test.want {
+			t.Errorf("formatVariant(%#v) = %q, want %q", test.value, got, test.want)
+		}
+	}
+}
+
+// fakeSystemctl puts a systemctl script printing output on PATH
+func fakeSystemctl(t *testing.T, script string) {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "systemctl"), []byte("#!/bin/sh\n"+script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestFallbackToSystemctl(t *testing.T) {
+	systemBus := connectSystemBus
+	t.Cleanup(func() { connectSystemBus = systemBus })
+	connectSystemBus = func() (*dbus.Conn, error) { return nil, os.ErrNotExist }
+
+	fakeSystemctl(t, `case "$*" in
+*--version*) echo "systemd 252";;
+*list-units*) printf '● db.service loaded failed failed DB\n  web.service loaded active running Web server\n';;
+*show*) printf 'MainPID=4242\nListen=0.0.0.0:80 (Stream)\nListen=[::]:80 (Stream)\n';;
+esac
+`)
+
+	units, err := listUnits(systemManager, "service")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []unitStatus{
+		{Name: "db.service", Description: "DB", LoadState: "loaded", ActiveState: "failed", SubState: "failed"},
+		{Name: "web.service", Description: "Web server", LoadState: "loaded", ActiveState: "active", SubState: "running"},
+	}
+	if !reflect.DeepEqual(units, want) {
+		t.Errorf("listUnits: got %+v, want %+v", units, want)
+	}
+
+	properties, err := unitProperties(systemManager, "web.socket", []string{"MainPID", "Listen"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantProperties := map[string]string{
+		"MainPID": "4242",
+		"Listen":  "0.0.0.0:80 (Stream)\n[::]:80 (Stream)",
+	}
+	if !reflect.DeepEqual(properties, wantProperties) {
+		t.Errorf("unitProperties: got %v, want %v", properties, wantProperties)
+	}
+}
+
+func TestOtherUsersManagerUsesSystemctl(t *testing.T) {
+	if _, err := (managerTarget{scope: ScopeUser, user: "someone-else"}).bus(); err == nil {
+		t.Error("expected another user's manager to be unreachable over D-Bus")
+	}
+}
@@ -0,0 +1,186 @@
+package systemd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"discover/models"
+)
+
+// DependencyTypes are the dependency properties the graph is built from
+var DependencyTypes = []string{"Requires", "Wants", "After", "PartOf"}
+
+// inverseDependencies maps the properties systemd keeps for the other end of each dependency,
+// so units that are not services (e.g. targets wanting a service) still show up as dependents
+var inverseDependencies = map[string]string{
+	"RequiredBy": "Requires",
+	"WantedBy":   "Wants",
+	"Before":     "After",
+	"ConsistsOf": "PartOf",
+}
+
+// GetDependencyGraph builds the Requires/Wants/After/PartOf graph between the services of the
+// manager the given service belongs to, with the active state of every unit in it
+func GetDependencyGraph(service models.SystemdService) (models.SystemdDependencyGraph, error) {
+	target := targetFor(service)
+	graph := models.SystemdDependencyGraph{States: make(map[string]string)}
+
+	units, err := listUnits(target, "")
+	if err != nil {
+		return graph, fmt.Errorf("error building the dependency graph: %v", err)
+	}
+	for _, unit := range units {
+		graph.States[unit.Name] = unit.ActiveState
+	}
+
+	names := append([]string{}, DependencyTypes...)
+	for inverse := range inverseDependencies {
+		names = append(names, inverse)
+	}
+
+	seen := make(map[models.SystemdDependency]bool)
+	addEdge := func(edge models.SystemdDependency) {
+		if !seen[edge] {
+			seen[edge] = true
+			graph.Edges = append(graph.Edges, edge)
+		}
+		// Units only referenced by a dependency are not loaded
+		for _, name := range []string{edge.From, edge.To} {
+			if _, ok := graph.States[name]; !ok {
+				graph.States[name] = "inactive"
+			}
+		}
+	}
+
+	for _, unit := range units {
+		if !strings.HasSuffix(unit.Name, ".service") {
+			continue
+		}
+
+		properties, err := unitProperties(target, unit.Name, names)
+		if err != nil {
+			fmt.Printf("Warning: Failed to read dependencies of %s: %v\n", unit.Name, err)
+			continue
+		}
+
+		for _, dependencyType := range DependencyTypes {
+			for _, other := range strings.Fields(properties[dependencyType]) {
+				addEdge(models.SystemdDependency{From: unit.Name, To: other, Type: dependencyType})
+			}
+		}
+		for inverse, dependencyType := range inverseDependencies {
+			for _, other := range strings.Fields(properties[inverse]) {
+				addEdge(models.SystemdDependency{From: other, To: unit.Name, Type: dependencyType})
+			}
+		}
+	}
+
+	sort.Slice(graph.Edges, func(i, j int) bool {
+		a, b := graph.Edges[i], graph.Edges[j]
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		return a.To < b.To
+	})
+
+	return graph, nil
+}
+
+// UnitDependencies returns the direct dependencies of a unit and the units that depend on it
+func UnitDependencies(graph models.SystemdDependencyGraph, unitName string) (dependsOn, dependedBy []models.SystemdDependency) {
+	for _, edge := range graph.Edges {
+		if edge.From == unitName {
+			dependsOn = append(dependsOn, edge)
+		}
+		if edge.To == unitName {
+			dependedBy = append(dependedBy, edge)
+		}
+	}
+	return dependsOn, dependedBy
+}
+
+// UnitSubgraph keeps the edges touching a unit, which is usually what is wanted in an incident doc
+func UnitSubgraph(graph models.SystemdDependencyGraph, unitName string) models.SystemdDependencyGraph {
+	dependsOn, dependedBy := UnitDependencies(graph, unitName)
+	subgraph := models.SystemdDependencyGraph{
+		States: map[string]string{unitName: graph.States[unitName]},
+		Edges:  append(dependsOn, dependedBy...),
+	}
+	for _, edge := range subgraph.Edges {
+		subgraph.States[edge.From] = graph.States[edge.From]
+		subgraph.States[edge.To] = graph.States[edge.To]
+	}
+	return subgraph
+}
+
+// GraphDOT renders a dependency graph in Graphviz DOT format, with failed units in red and
+// ordering-only (After) edges dashed
+func GraphDOT(graph models.SystemdDependencyGraph) string {
+	var b strings.Builder
+	b.WriteString("digraph units {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box];\n")
+
+	for _, name := range graphUnits(graph) {
+		if graph.States[name] == "failed" {
+			fmt.Fprintf(&b, "  %q [color=red, style=filled, fillcolor=\"#ffcccc\"];\n", name)
+		} else {
+			fmt.Fprintf(&b, "  %q;\n", name)
+		}
+	}
+	for _, edge := range graph.Edges {
+		style := ""
+		if edge.Type == "After" {
+			style = ", style=dashed"
+		}
+		fmt.Fprintf(&b, "  %q -> %q [label=%q%s];\n", edge.From, edge.To, edge.Type, style)
+	}
+
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// GraphMermaid renders a dependency graph as a Mermaid flowchart, with failed units highlighted
+// and ordering-only (After) edges dotted
+func GraphMermaid(graph models.SystemdDependencyGraph) string {
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+
+	// Unit names contain characters Mermaid does not allow in node ids
+	ids := make(map[string]string)
+	var failed []string
+	for i, name := range graphUnits(graph) {
+		ids[name] = fmt.Sprintf("u%d", i)
+		fmt.Fprintf(&b, "  %s[\"%s\"]\n", ids[name], strings.ReplaceAll(name, `"`, "#quot;"))
+		if graph.States[name] == "failed" {
+			failed = append(failed, ids[name])
+		}
+	}
+	for _, edge := range graph.Edges {
+		arrow := "-->"
+		if edge.Type == "After" {
+			arrow = "-.->"
+		}
+		fmt.Fprintf(&b, "  %s %s|%s| %s\n", ids[edge.From], arrow, edge.Type, ids[edge.To])
+	}
+
+	if len(failed) > 0 {
+		b.WriteString("  classDef failed fill:#ffcccc,stroke:#cc0000,color:#000\n")
+		fmt.Fprintf(&b, "  class %s failed\n", strings.Join(failed, ","))
+	}
+	return b.String()
+}
+
+// graphUnits returns the units of a graph in a stable order
+func graphUnits(graph models.SystemdDependencyGraph) []string {
+	names := make([]string, 0, len(graph.States))
+	for name := range graph.States {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
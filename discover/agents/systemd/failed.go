@@ -0,0 +1,96 @@
+package systemd
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"discover/models"
+)
+
+// FailedJournalLines is how many journal lines are collected for each failed unit
+const FailedJournalLines = 20
+
+// failedUnitProperties are the properties read for the triage of a failed unit
+var failedUnitProperties = []string{"Result", "ExecMainCode", "ExecMainStatus", "NRestarts", "StateChangeTimestamp"}
+
+// Values of ExecMainCode, from the CLD_* codes of waitid(2)
+const (
+	cldExited = "1"
+	cldKilled = "2"
+	cldDumped = "3"
+)
+
+// GetFailedUnits returns every unit in the failed state, from the system manager and the visible
+// user managers, with why it failed and the journal lines leading up to the failure
+func GetFailedUnits() []models.SystemdFailedUnit {
+	var failed []models.SystemdFailedUnit
+
+	for _, unit := range listManagedUnits("") {
+		if unit.ActiveState != "failed" {
+			continue
+		}
+
+		failedUnit := models.SystemdFailedUnit{
+			Name:        unit.Name,
+			Description: unit.Description,
+		}
+		failedUnit.Scope, failedUnit.User = unit.target.scopeFields()
+
+		properties, err := unitProperties(unit.target, unit.Name, failedUnitProperties)
+		if err != nil {
+			fmt.Printf("Warning: Failed to read properties of %s: %v\n", unit.Name, err)
+		} else {
+			failedUnit.Result = properties["Result"]
+			failedUnit.ExitStatus = exitStatus(properties["ExecMainCode"], properties["ExecMainStatus"])
+			failedUnit.NRestarts, _ = strconv.Atoi(properties["NRestarts"])
+			failedUnit.FailedAt = parseTimestamp(properties["StateChangeTimestamp"])
+		}
+
+		failedUnit.Journal = failureJournal(unit.target, unit.Name, failedUnit.FailedAt)
+		failed = append(failed, failedUnit)
+	}
+
+	return failed
+}
+
+// exitStatus describes how a unit's main process ended, or "" for units without one
+func exitStatus(code, status string) string {
+	number, err := strconv.Atoi(status)
+	if err != nil {
+		return ""
+	}
+
+	switch code {
+	case cldExited:
+		return fmt.Sprintf("exit code %d", number)
+	case cldKilled:
+		return fmt.Sprintf("signal %d (%s)", number, syscall.Signal(number))
+	case cldDumped:
+		return fmt.Sprintf("signal %d (%s), core dumped", number, syscall.Signal(number))
+	}
+	return ""
+}
+
+// failureJournal returns the last journal lines of a unit up to shortly after it failed
+func failureJournal(target managerTarget, unitName string, failedAt time.Time) []string {
+	args := append(target.journalArgs(unitName), "--no-pager", "-n", strconv.Itoa(FailedJournalLines))
+	if !failedAt.IsZero() {
+		// Leave a little room for the lines systemd logs right after the failure
+		args = append(args, "--until", failedAt.Add(5*time.Second).Format("2006-01-02 15:04:05"))
+	}
+
+	output, err := exec.Command("journalctl", args...).CombinedOutput()
+	if err != nil {
+		return []string{fmt.Sprintf("Error retrieving logs for %s: %v", unitName, err)}
+	}
+
+	text := strings.TrimSpace(string(output))
+	if text == "" || text == "-- No entries --" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
@@ -0,0 +1,171 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sync"
+
+	"discover/models"
+)
+
+const configFileName = "config.json"
+
+// Config holds the user settings that change how discovery behaves
+type Config struct {
+	Kubernetes KubernetesSettings `json:"kubernetes"`
+	Systemd    SystemdSettings    `json:"systemd"`
+	Libvirt    LibvirtSettings    `json:"libvirt"`
+	Containerd ContainerdSettings `json:"containerd"`
+	State      StateSettings      `json:"state"`
+}
+
+// StateSettings holds the settings for the saved system state
+type StateSettings struct {
+	// MaxSnapshots is how many snapshots are kept, removing the oldest first; all are kept when 0
+	MaxSnapshots int `json:"max_snapshots,omitempty"`
+}
+
+// ContainerdSettings holds the settings for the containerd agent
+type ContainerdSettings struct {
+	// Socket is the containerd socket, e.g. "/run/k3s/containerd/containerd.sock"; the defaults of
+	// crictl and nerdctl are used when empty
+	Socket string `json:"socket,omitempty"`
+}
+
+// LibvirtSettings holds the settings for the libvirt agent
+type LibvirtSettings struct {
+	// URI is the libvirt connection URI, e.g. "qemu:///system"; virsh's default is used when empty
+	URI string `json:"uri,omitempty"`
+}
+
+// SystemdSettings holds the settings for the systemd agent
+type SystemdSettings struct {
+	// AllUsers also lists the user units of every logged-in user when running as root
+	AllUsers bool `json:"all_users,omitempty"`
+	// ImportantServices are glob patterns of services whose full details are captured into state
+	ImportantServices []string `json:"important_services,omitempty"`
+}
+
+// Important reports whether a service (named without the .service suffix) is marked important
+func (s SystemdSettings) Important(serviceName string) bool {
+	return matchesAny(serviceName, s.ImportantServices)
+}
+
+// KubernetesSettings holds the settings for the Kubernetes agent.
+// Context and namespace patterns use shell glob syntax, e.g. "prod-*".
+type KubernetesSettings struct {
+	// IncludeContexts limits discovery to matching contexts when set
+	IncludeContexts []string `json:"include_contexts,omitempty"`
+	// ExcludeContexts skips matching contexts
+	ExcludeContexts []string `json:"exclude_contexts,omitempty"`
+	// IncludeNamespaces limits discovery to matching namespaces when set
+	IncludeNamespaces []string `json:"include_namespaces,omitempty"`
+	// ExcludeNamespaces skips matching namespaces
+	ExcludeNamespaces []string `json:"exclude_namespaces,omitempty"`
+	// ActiveContextOnly only probes the current kubectl context
+	ActiveContextOnly bool `json:"active_context_only,omitempty"`
+	// CustomResources lists extra resource kinds to discover in every namespace
+	CustomResources []models.KubernetesResourceKind `json:"custom_resources,omitempty"`
+}
+
+// ContextAllowed reports whether a context passes the include/exclude patterns
+func (s KubernetesSettings) ContextAllowed(contextName string) bool {
+	return allowed(contextName, s.IncludeContexts, s.ExcludeContexts)
+}
+
+// NamespaceAllowed reports whether a namespace passes the include/exclude patterns
+func (s KubernetesSettings) NamespaceAllowed(namespaceName string) bool {
+	return allowed(namespaceName, s.IncludeNamespaces, s.ExcludeNamespaces)
+}
+
+// HasFilters reports whether any context or namespace filtering is configured
+func (s KubernetesSettings) HasFilters() bool {
+	return s.ActiveContextOnly || len(s.IncludeContexts) > 0 || len(s.ExcludeContexts) > 0 ||
+		len(s.IncludeNamespaces) > 0 || len(s.ExcludeNamespaces) > 0
+}
+
+// allowed reports whether a name matches one of the include patterns (if any) and none of the exclude patterns
+func allowed(name string, include, exclude []string) bool {
+	if len(include) > 0 && !matchesAny(name, include) {
+		return false
+	}
+	return !matchesAny(name, exclude)
+}
+
+// matchesAny reports whether a name matches any of the glob patterns
+func matchesAny(name string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if matched, err := path.Match(pattern, name); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	current     Config
+	currentOnce sync.Once
+	currentLock sync.RWMutex
+)
+
+// GetConfigFilePath returns the path to the config file
+func GetConfigFilePath() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		// Fallback to current directory if we can't get home dir
+		return configFileName
+	}
+
+	return filepath.Join(homeDir, ".discover", configFileName)
+}
+
+// LoadConfig loads the settings from the config file
+func LoadConfig() (Config, error) {
+	var cfg Config
+
+	data, err := ioutil.ReadFile(GetConfigFilePath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			// Use the defaults if there is no config file
+			return cfg, nil
+		}
+		return cfg, fmt.Errorf("error reading config file: %v", err)
+	}
+
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("error parsing config file: %v", err)
+	}
+
+	return cfg, nil
+}
+
+// Current returns the settings in effect, loading the config file on first use
+func Current() Config {
+	currentOnce.Do(func() {
+		cfg, err := LoadConfig()
+		if err != nil {
+			fmt.Printf("Warning: %v, using defaults\n", err)
+		}
+		currentLock.Lock()
+		current = cfg
+		currentLock.Unlock()
+	})
+
+	currentLock.RLock()
+	defer currentLock.RUnlock()
+	return current
+}
+
+// Set replaces the settings in effect, e.g. after applying command line flags
+func Set(cfg Config) {
+	// Make sure a later Current call does not overwrite these settings with the file
+	currentOnce.Do(func() {})
+
+	currentLock.Lock()
+	current = cfg
+	currentLock.Unlock()
+}
@@ -2,7 +2,10 @@ module discover
 
 go 1.23.6
 
-require github.com/manifoldco/promptui v0.9.0
+require (
+	github.com/godbus/dbus/v5 v5.1.0
+	github.com/manifoldco/promptui v0.9.0
+)
 
 require (
 	github.com/chzyer/readline v0.0.0-20180603132655-2972be24d48e // indirect
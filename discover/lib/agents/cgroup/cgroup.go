@@ -0,0 +1,201 @@
+package cgroup
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os/user"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/shellcanary/discover/lib/agents/docker"
+	"github.com/shellcanary/discover/lib/agents/kubernetes"
+	"github.com/shellcanary/discover/lib/agents/systemd"
+	"github.com/shellcanary/discover/lib/models"
+)
+
+// Kinds of resources that can own a cgroup
+const (
+	OwnerSystemd    = "systemd"
+	OwnerDocker     = "docker"
+	OwnerKubernetes = "kubernetes"
+)
+
+var (
+	// podUIDPattern matches the pod UID in kubepods cgroups, written with underscores by the systemd driver
+	podUIDPattern = regexp.MustCompile(`pod([0-9a-f]{8}[-_][0-9a-f]{4}[-_][0-9a-f]{4}[-_][0-9a-f]{4}[-_][0-9a-f]{12})`)
+	// dockerIDPattern matches "docker-<id>.scope" (systemd driver) and "/docker/<id>" (cgroupfs driver)
+	dockerIDPattern = regexp.MustCompile(`docker[-/]([0-9a-f]{64})`)
+	// userManagerPattern matches the cgroup of a user manager, e.g. "user@1000.service"
+	userManagerPattern = regexp.MustCompile(`^user@(\d+)\.service$`)
+)
+
+// unitSuffixes are the unit types that get their own cgroup
+var unitSuffixes = []string{".service", ".scope", ".socket", ".mount", ".swap"}
+
+// Resolver works out which resource owns a cgroup. Container and pod names are looked up
+// once, the first time a container or pod cgroup is seen.
+type Resolver struct {
+	containers map[string]string
+	pods       map[string]string
+}
+
+// NewResolver returns a resolver with empty lookup caches
+func NewResolver() *Resolver {
+	return &Resolver{}
+}
+
+// PathOfPID returns the cgroup path of a running process, preferring the unified (v2) hierarchy
+func PathOfPID(pid int) (string, error) {
+	data, err := ioutil.ReadFile(fmt.Sprintf("/proc/%d/cgroup", pid))
+	if err != nil {
+		return "", fmt.Errorf("error reading cgroup of process %d: %v", pid, err)
+	}
+
+	// Lines look like "hierarchy-ID:controllers:path"; v2 uses "0::path"
+	paths := make(map[string]string)
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		parts := strings.SplitN(line, ":", 3)
+		if len(parts) == 3 {
+			paths[parts[0]+":"+parts[1]] = parts[2]
+		}
+	}
+	for _, key := range []string{"0:", "1:name=systemd"} {
+		if cgroupPath, ok := paths[key]; ok {
+			return cgroupPath, nil
+		}
+	}
+	for key, cgroupPath := range paths {
+		if strings.HasSuffix(key, ":memory") || strings.Contains(key, ",memory") {
+			return cgroupPath, nil
+		}
+	}
+	return "", fmt.Errorf("no cgroup found for process %d", pid)
+}
+
+// OwnerOfPID returns the owner of a running process
+func (r *Resolver) OwnerOfPID(pid int) models.CgroupOwner {
+	cgroupPath, err := PathOfPID(pid)
+	if err != nil {
+		return models.CgroupOwner{}
+	}
+	return r.Owner(cgroupPath)
+}
+
+// Owner returns the Kubernetes pod, Docker container or systemd unit a cgroup belongs to
+func (r *Resolver) Owner(cgroupPath string) models.CgroupOwner {
+	owner := models.CgroupOwner{Cgroup: cgroupPath}
+
+	if strings.Contains(cgroupPath, "kubepods") {
+		if match := podUIDPattern.FindStringSubmatch(cgroupPath); match != nil {
+			owner.Kind = OwnerKubernetes
+			owner.ID = strings.ReplaceAll(match[1], "_", "-")
+			owner.Name = r.podName(owner.ID)
+			return owner
+		}
+	}
+
+	if match := dockerIDPattern.FindStringSubmatch(cgroupPath); match != nil {
+		owner.Kind = OwnerDocker
+		owner.ID = match[1]
+		owner.Name = r.containerName(owner.ID)
+		return owner
+	}
+
+	// The innermost unit is the owner; units below a user manager belong to that user
+	segments := strings.Split(strings.Trim(cgroupPath, "/"), "/")
+	for i := len(segments) - 1; i >= 0; i-- {
+		if !isUnit(segments[i]) {
+			continue
+		}
+		owner.Kind = OwnerSystemd
+		owner.Name = segments[i]
+		owner.Scope = systemd.ScopeSystem
+		for _, parent := range segments[:i] {
+			if match := userManagerPattern.FindStringSubmatch(parent); match != nil {
+				owner.Scope = systemd.ScopeUser
+				owner.User = match[1]
+				if account, err := user.LookupId(match[1]); err == nil {
+					owner.User = account.Username
+				}
+			}
+		}
+		return owner
+	}
+
+	return owner
+}
+
+// isUnit reports whether a cgroup path segment is named after a unit
+func isUnit(segment string) bool {
+	for _, suffix := range unitSuffixes {
+		if strings.HasSuffix(segment, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// containerName looks up the name of a Docker container, falling back to its short ID
+func (r *Resolver) containerName(id string) string {
+	if r.containers == nil {
+		r.containers, _ = docker.ContainerNamesByID()
+		if r.containers == nil {
+			r.containers = make(map[string]string)
+		}
+	}
+	if name, ok := r.containers[id]; ok {
+		return name
+	}
+	return id[:12]
+}
+
+// podName looks up "namespace/name" of a pod in the current context, falling back to its UID
+func (r *Resolver) podName(uid string) string {
+	if r.pods == nil {
+		r.pods, _ = kubernetes.PodsByUID("")
+		if r.pods == nil {
+			r.pods = make(map[string]string)
+		}
+	}
+	if name, ok := r.pods[uid]; ok {
+		return name
+	}
+	return uid
+}
+
+// Describe names an owner for display, e.g. "systemd unit nginx.service"
+func Describe(owner models.CgroupOwner) string {
+	switch owner.Kind {
+	case OwnerSystemd:
+		if owner.Scope == systemd.ScopeUser {
+			return fmt.Sprintf("systemd user unit %s (%s)", owner.Name, owner.User)
+		}
+		return "systemd unit " + owner.Name
+	case OwnerDocker:
+		return "Docker container " + owner.Name
+	case OwnerKubernetes:
+		return "Kubernetes pod " + owner.Name
+	}
+	if owner.Cgroup != "" && owner.Cgroup != "/" {
+		return "cgroup " + path.Clean(owner.Cgroup)
+	}
+	return "unknown"
+}
+
+// Logs retrieves the recent logs of an owner from the journal, Docker or Kubernetes
+func Logs(owner models.CgroupOwner) string {
+	switch owner.Kind {
+	case OwnerSystemd:
+		return systemd.GetServiceLogs(models.SystemdService{Name: owner.Name, Scope: owner.Scope, User: owner.User})
+	case OwnerDocker:
+		return docker.GetContainerLogs(owner.ID)
+	case OwnerKubernetes:
+		parts := strings.SplitN(owner.Name, "/", 2)
+		if len(parts) != 2 {
+			return fmt.Sprintf("Pod %s is not in the current Kubernetes context", owner.ID)
+		}
+		return kubernetes.GetPodLogs("", parts[0], parts[1])
+	}
+	return fmt.Sprintf("No logs available for %s", Describe(owner))
+}
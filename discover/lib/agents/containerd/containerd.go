@@ -0,0 +1,241 @@
+package containerd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/shellcanary/discover/lib/config"
+	"github.com/shellcanary/discover/lib/models"
+)
+
+// logLines is how many log lines are shown for a container
+const logLines = 100
+
+// nerdctl runs a nerdctl command in a containerd namespace against the configured socket
+func nerdctl(namespace string, args ...string) ([]byte, error) {
+	prefix := []string{}
+	if socket := config.Current().Containerd.Socket; socket != "" {
+		prefix = append(prefix, "--address", socket)
+	}
+	if namespace != "" {
+		prefix = append(prefix, "--namespace", namespace)
+	}
+
+	output, err := exec.Command("nerdctl", append(prefix, args...)...).Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return nil, fmt.Errorf("nerdctl %s failed: %v\n%s", strings.Join(args, " "), err, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, fmt.Errorf("nerdctl failed: %v", err)
+	}
+	return output, nil
+}
+
+// GetNamespaces returns the containerd namespaces, using nerdctl or else ctr, so they can be
+// listed on nodes with only ctr installed
+func GetNamespaces() ([]string, error) {
+	output, err := nerdctl("", "namespace", "ls", "--quiet")
+	if err != nil {
+		args := []string{"namespaces", "ls", "--quiet"}
+		if socket := config.Current().Containerd.Socket; socket != "" {
+			args = append([]string{"--address", socket}, args...)
+		}
+		var ctrErr error
+		if output, ctrErr = exec.Command("ctr", args...).Output(); ctrErr != nil {
+			if exitErr, ok := ctrErr.(*exec.ExitError); ok {
+				ctrErr = fmt.Errorf("%v\n%s", ctrErr, strings.TrimSpace(string(exitErr.Stderr)))
+			}
+			return nil, fmt.Errorf("error listing containerd namespaces: %v; ctr failed: %v", err, ctrErr)
+		}
+	}
+	return strings.Fields(string(output)), nil
+}
+
+// GetRuntimeContainers returns the containers of every containerd namespace: Kubernetes
+// containers from crictl with their pods, and the others from nerdctl
+func GetRuntimeContainers() ([]models.RuntimeContainer, error) {
+	var containers []models.RuntimeContainer
+	var failures []string
+
+	criFound, err := criContainers()
+	if err != nil {
+		failures = append(failures, err.Error())
+	}
+	containers = append(containers, criFound...)
+
+	namespaces, err := nerdctlNamespaces(err == nil)
+	if err != nil {
+		failures = append(failures, err.Error())
+	}
+	for _, namespace := range namespaces {
+		found, err := nerdctlContainers(namespace)
+		if err != nil {
+			failures = append(failures, err.Error())
+			continue
+		}
+		containers = append(containers, found...)
+	}
+
+	if len(containers) == 0 && len(failures) > 0 {
+		return nil, fmt.Errorf("error listing containerd containers: %s", strings.Join(failures, "; "))
+	}
+	return containers, nil
+}
+
+// GetRuntimeImages returns the images of every containerd namespace
+func GetRuntimeImages() ([]models.RuntimeImage, error) {
+	var images []models.RuntimeImage
+	var failures []string
+
+	criFound, err := criImages()
+	if err != nil {
+		failures = append(failures, err.Error())
+	}
+	images = append(images, criFound...)
+
+	namespaces, err := nerdctlNamespaces(err == nil)
+	if err != nil {
+		failures = append(failures, err.Error())
+	}
+	for _, namespace := range namespaces {
+		found, err := nerdctlImages(namespace)
+		if err != nil {
+			failures = append(failures, err.Error())
+			continue
+		}
+		images = append(images, found...)
+	}
+
+	if len(images) == 0 && len(failures) > 0 {
+		return nil, fmt.Errorf("error listing containerd images: %s", strings.Join(failures, "; "))
+	}
+	return images, nil
+}
+
+// GetRuntimeContainerLogs returns the last lines of a container's log: read from the CRI log file
+// for Kubernetes containers (which needs root), and through nerdctl for the others
+func GetRuntimeContainerLogs(container *models.RuntimeContainer) string {
+	if container.Namespace != criNamespace {
+		output, err := nerdctl(container.Namespace, "logs", "--tail", fmt.Sprint(logLines), container.ID)
+		if err != nil {
+			return fmt.Sprintf("Error retrieving logs for container %s: %v", container.Name, err)
+		}
+		return string(output)
+	}
+
+	if container.LogPath == "" {
+		path, err := criLogPath(container.ID)
+		if err != nil {
+			return fmt.Sprintf("Error retrieving logs for container %s: %v", container.Name, err)
+		}
+		container.LogPath = path
+	}
+	logs, err := readCRILog(container.LogPath, logLines)
+	if err != nil {
+		return fmt.Sprintf("Error retrieving logs for container %s: %v", container.Name, err)
+	}
+	return logs
+}
+
+// nerdctlNamespaces returns the namespaces to list with nerdctl, leaving out the CRI namespace
+// when crictl already covered it. They are asked from nerdctl itself, as listing their
+// containers and images needs it anyway.
+func nerdctlNamespaces(criListed bool) ([]string, error) {
+	output, err := nerdctl("", "namespace", "ls", "--quiet")
+	if err != nil {
+		return nil, err
+	}
+
+	var selected []string
+	for _, namespace := range strings.Fields(string(output)) {
+		if namespace != criNamespace || !criListed {
+			selected = append(selected, namespace)
+		}
+	}
+	return selected, nil
+}
+
+// nerdctlContainers lists the containers of a namespace; nerdctl prints one JSON object per line
+func nerdctlContainers(namespace string) ([]models.RuntimeContainer, error) {
+	output, err := nerdctl(namespace, "ps", "--all", "--no-trunc", "--format", "{{json .}}")
+	if err != nil {
+		return nil, err
+	}
+
+	var containers []models.RuntimeContainer
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if line == "" {
+			continue
+		}
+		var item struct {
+			ID        string `json:"ID"`
+			Names     string `json:"Names"`
+			Image     string `json:"Image"`
+			Status    string `json:"Status"`
+			CreatedAt string `json:"CreatedAt"`
+		}
+		if err := json.Unmarshal([]byte(line), &item); err != nil {
+			return containers, fmt.Errorf("error parsing nerdctl containers: %v", err)
+		}
+
+		container := models.RuntimeContainer{
+			ID:        item.ID,
+			Name:      item.Names,
+			Image:     item.Image,
+			State:     nerdctlState(item.Status),
+			Namespace: namespace,
+		}
+		container.Created, _ = time.Parse("2006-01-02 15:04:05 -0700 MST", item.CreatedAt)
+		if container.Name == "" && len(item.ID) >= 12 {
+			container.Name = item.ID[:12]
+		}
+		containers = append(containers, container)
+	}
+	return containers, nil
+}
+
+// nerdctlState turns a status such as "Up 2 hours" or "Exited (0) 3 days ago" into a state
+func nerdctlState(status string) string {
+	switch {
+	case strings.HasPrefix(status, "Up"):
+		return "running"
+	case strings.HasPrefix(status, "Exited"):
+		return "exited"
+	case status == "":
+		return "unknown"
+	}
+	return strings.ToLower(strings.Fields(status)[0])
+}
+
+// nerdctlImages lists the images of a namespace
+func nerdctlImages(namespace string) ([]models.RuntimeImage, error) {
+	output, err := nerdctl(namespace, "images", "--format", "{{json .}}")
+	if err != nil {
+		return nil, err
+	}
+
+	var images []models.RuntimeImage
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if line == "" {
+			continue
+		}
+		var item struct {
+			ID         string `json:"ID"`
+			Repository string `json:"Repository"`
+			Tag        string `json:"Tag"`
+		}
+		if err := json.Unmarshal([]byte(line), &item); err != nil {
+			return images, fmt.Errorf("error parsing nerdctl images: %v", err)
+		}
+
+		image := models.RuntimeImage{ID: item.ID, Namespace: namespace}
+		if item.Repository != "" && item.Repository != "<none>" {
+			image.Tags = []string{item.Repository + ":" + item.Tag}
+		}
+		images = append(images, image)
+	}
+	return images, nil
+}
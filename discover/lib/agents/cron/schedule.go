@@ -0,0 +1,168 @@
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule is a parsed five-field cron expression
+type Schedule struct {
+	minutes, hours, days, months, weekdays map[int]bool
+	// anyDay and anyWeekday record a day-of-month or day-of-week starting with "*": unless one of
+	// them does, cron runs on days matching either field
+	anyDay, anyWeekday bool
+}
+
+// scheduleMacros are the "@" shorthands cron accepts
+var scheduleMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var (
+	monthNames   = []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}
+	weekdayNames = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+)
+
+// ParseSchedule parses a cron expression such as "*/5 * * * 1-5" or "@daily". @reboot has no
+// schedule and is rejected.
+func ParseSchedule(expression string) (*Schedule, error) {
+	if macro, ok := scheduleMacros[strings.ToLower(expression)]; ok {
+		expression = macro
+	}
+	fields := strings.Fields(expression)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid cron schedule %q: expected 5 fields", expression)
+	}
+
+	schedule := &Schedule{anyDay: strings.HasPrefix(fields[2], "*"), anyWeekday: strings.HasPrefix(fields[4], "*")}
+	var err error
+	if schedule.minutes, err = parseField(fields[0], 0, 59, nil); err != nil {
+		return nil, err
+	}
+	if schedule.hours, err = parseField(fields[1], 0, 23, nil); err != nil {
+		return nil, err
+	}
+	if schedule.days, err = parseField(fields[2], 1, 31, nil); err != nil {
+		return nil, err
+	}
+	if schedule.months, err = parseField(fields[3], 1, 12, monthNames); err != nil {
+		return nil, err
+	}
+	// Sunday is both 0 and 7
+	if schedule.weekdays, err = parseField(fields[4], 0, 7, weekdayNames); err != nil {
+		return nil, err
+	}
+	if schedule.weekdays[7] {
+		schedule.weekdays[0] = true
+	}
+	return schedule, nil
+}
+
+// parseField parses a comma-separated list of values, ranges and steps, such as "1-5,*/15".
+// names, when given, are accepted in place of numbers starting from min (or 1 for months).
+func parseField(field string, min, max int, names []string) (map[int]bool, error) {
+	values := make(map[int]bool)
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if slash := strings.Index(part, "/"); slash != -1 {
+			var err error
+			step, err = strconv.Atoi(part[slash+1:])
+			if err != nil || step < 1 {
+				return nil, fmt.Errorf("invalid step in cron field %q", field)
+			}
+			part = part[:slash]
+		}
+
+		low, high := min, max
+		switch {
+		case part == "*":
+		case strings.Contains(part, "-"):
+			bounds := strings.SplitN(part, "-", 2)
+			var err error
+			if low, err = fieldValue(bounds[0], min, names); err != nil {
+				return nil, fmt.Errorf("invalid cron field %q", field)
+			}
+			if high, err = fieldValue(bounds[1], min, names); err != nil {
+				return nil, fmt.Errorf("invalid cron field %q", field)
+			}
+		default:
+			value, err := fieldValue(part, min, names)
+			if err != nil {
+				return nil, fmt.Errorf("invalid cron field %q", field)
+			}
+			low = value
+			// "5/10" means from 5 to the maximum in steps of 10
+			if step == 1 {
+				high = value
+			}
+		}
+
+		if low < min || high > max || low > high {
+			return nil, fmt.Errorf("cron field %q is out of range %d-%d", field, min, max)
+		}
+		for value := low; value <= high; value += step {
+			values[value] = true
+		}
+	}
+	return values, nil
+}
+
+// fieldValue parses a number or a month or weekday name
+func fieldValue(text string, min int, names []string) (int, error) {
+	for i, name := range names {
+		if strings.EqualFold(text, name) {
+			if len(names) == len(monthNames) {
+				return i + 1, nil
+			}
+			return i, nil
+		}
+	}
+	return strconv.Atoi(text)
+}
+
+// Next returns the first time after from at which the schedule runs, or the zero time if it
+// never does (e.g. February 30th)
+func (s *Schedule) Next(from time.Time) time.Time {
+	t := from.Truncate(time.Minute).Add(time.Minute)
+	// A schedule that matches at all matches within a few years (leap days need up to 8)
+	limit := t.AddDate(9, 0, 0)
+
+	for t.Before(limit) {
+		if !s.months[int(t.Month())] {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.hours[t.Hour()] {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.minutes[t.Minute()] {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// dayMatches applies cron's day rule: if either day field starts with "*" (including steps such
+// as "*/2") both must match, otherwise either may
+func (s *Schedule) dayMatches(t time.Time) bool {
+	day, weekday := s.days[t.Day()], s.weekdays[int(t.Weekday())]
+	if s.anyDay || s.anyWeekday {
+		return day && weekday
+	}
+	return day || weekday
+}
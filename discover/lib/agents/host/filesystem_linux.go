@@ -0,0 +1,76 @@
+//go:build linux
+
+package host
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"syscall"
+
+	"github.com/shellcanary/discover/lib/models"
+)
+
+// pseudoFilesystems have no disk space worth reporting
+var pseudoFilesystems = map[string]bool{
+	"proc": true, "sysfs": true, "devtmpfs": true, "devpts": true, "cgroup": true, "cgroup2": true,
+	"securityfs": true, "pstore": true, "bpf": true, "debugfs": true, "tracefs": true, "configfs": true,
+	"fusectl": true, "mqueue": true, "hugetlbfs": true, "autofs": true, "binfmt_misc": true,
+	"efivarfs": true, "rpc_pipefs": true, "nsfs": true, "squashfs": true, "overlay": true,
+}
+
+// GetFilesystemUsage returns the space and inode usage of mounted disk filesystems, ordered by
+// mount point. Pseudo filesystems and mounts we cannot stat are left out.
+func GetFilesystemUsage() ([]models.FilesystemUsage, error) {
+	file, err := os.Open("/proc/mounts")
+	if err != nil {
+		return nil, fmt.Errorf("error reading mounts: %v", err)
+	}
+	defer file.Close()
+
+	var filesystems []models.FilesystemUsage
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		// Lines look like "/dev/sda1 / ext4 rw,relatime 0 0", with spaces in paths written as \040
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 3 || pseudoFilesystems[fields[2]] {
+			continue
+		}
+		mount := unescapeMount(fields[1])
+		if seen[mount] {
+			continue
+		}
+
+		var stat syscall.Statfs_t
+		if err := syscall.Statfs(mount, &stat); err != nil || stat.Blocks == 0 {
+			continue
+		}
+		seen[mount] = true
+
+		blockSize := uint64(stat.Bsize)
+		filesystems = append(filesystems, models.FilesystemUsage{
+			Mount:      mount,
+			Device:     fields[0],
+			Type:       fields[2],
+			Size:       stat.Blocks * blockSize,
+			Used:       (stat.Blocks - stat.Bfree) * blockSize,
+			Available:  stat.Bavail * blockSize,
+			Inodes:     stat.Files,
+			InodesUsed: stat.Files - stat.Ffree,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return filesystems, fmt.Errorf("error reading mounts: %v", err)
+	}
+
+	sort.Slice(filesystems, func(i, j int) bool { return filesystems[i].Mount < filesystems[j].Mount })
+	return filesystems, nil
+}
+
+// unescapeMount decodes the octal escapes /proc/mounts uses for spaces, tabs and newlines
+func unescapeMount(path string) string {
+	return strings.NewReplacer(`\040`, " ", `\011`, "\t", `\012`, "\n", `\134`, `\`).Replace(path)
+}
@@ -0,0 +1,14 @@
+//go:build !linux
+
+package host
+
+import (
+	"fmt"
+
+	"github.com/shellcanary/discover/lib/models"
+)
+
+// GetFilesystemUsage is only supported on Linux, where mounts are listed in /proc/mounts
+func GetFilesystemUsage() ([]models.FilesystemUsage, error) {
+	return nil, fmt.Errorf("filesystem usage is not supported on this platform")
+}
@@ -0,0 +1,156 @@
+package kernel
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shellcanary/discover/lib/agents/cgroup"
+	"github.com/shellcanary/discover/lib/agents/systemd"
+	"github.com/shellcanary/discover/lib/models"
+)
+
+// Kinds of kernel events
+const (
+	EventOOMKill  = "oom-kill"
+	EventSegfault = "segfault"
+	EventHungTask = "hung-task"
+)
+
+// journalLines is how many kernel messages are read from the journal per boot
+const journalLines = 20000
+
+var (
+	// oomKillPattern matches the summary line the OOM killer prints before killing, which carries
+	// the cgroup of the victim (kernel 4.19+), e.g.
+	// "oom-kill:constraint=CONSTRAINT_MEMCG,...,task_memcg=/system.slice/foo.service,task=foo,pid=123,uid=0"
+	oomKillPattern = regexp.MustCompile(`oom-kill:.*task_memcg=([^,]*),task=([^,]*),pid=(\d+)`)
+	// oomKilledPattern matches "Out of memory: Killed process 123 (foo)" and its memory cgroup variant
+	oomKilledPattern = regexp.MustCompile(`(?:Out of memory|Memory cgroup out of memory): Kill(?:ed)? process (\d+) \(([^)]*)\)`)
+	// segfaultPattern matches "foo[123]: segfault at 0 ip ..."
+	segfaultPattern = regexp.MustCompile(`^(.+)\[(\d+)\]: segfault at`)
+	// hungTaskPattern matches "INFO: task foo:123 blocked for more than 120 seconds."
+	hungTaskPattern = regexp.MustCompile(`task (.+):(\d+) blocked for more than \d+ seconds`)
+)
+
+// kernelMessage is a kernel log line from either the journal or the ring buffer
+type kernelMessage struct {
+	Timestamp time.Time
+	Message   string
+}
+
+// GetKernelEvents returns the OOM kills, segfaults and hung tasks logged by the kernel during a boot
+// (0 for the current one, -1 for the previous one), with the resource each process belonged to
+func GetKernelEvents(boot int) ([]models.KernelEvent, error) {
+	messages, err := journalMessages(boot)
+	// Users outside the adm and systemd-journal groups read the journal without error but see no
+	// kernel messages. Without them only the ring buffer of the current boot is available.
+	if err != nil || len(messages) == 0 {
+		if boot != 0 {
+			return nil, err
+		}
+		messages, err = ringBufferMessages()
+		if err != nil {
+			return nil, fmt.Errorf("error reading kernel messages: %v", err)
+		}
+	}
+
+	return parseEvents(messages, cgroup.NewResolver()), nil
+}
+
+// journalMessages reads the kernel messages of a boot from the journal
+func journalMessages(boot int) ([]kernelMessage, error) {
+	entries, err := systemd.GetJournal([]string{"_TRANSPORT=kernel"}, systemd.JournalQuery{Boot: boot, Lines: journalLines})
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make([]kernelMessage, 0, len(entries))
+	for _, entry := range entries {
+		messages = append(messages, kernelMessage{Timestamp: entry.Timestamp, Message: entry.Message})
+	}
+	return messages, nil
+}
+
+// ringBufferMessages reads the kernel ring buffer with dmesg, which may need root
+func ringBufferMessages() ([]kernelMessage, error) {
+	output, err := exec.Command("dmesg", "--time-format", "iso").Output()
+	if err != nil {
+		return nil, fmt.Errorf("dmesg failed: %v", err)
+	}
+
+	var messages []kernelMessage
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		// Lines look like "2026-10-19T02:00:00,123456+00:00 message"
+		parts := strings.SplitN(line, " ", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		timestamp, _ := time.Parse("2006-01-02T15:04:05,000000-07:00", parts[0])
+		messages = append(messages, kernelMessage{Timestamp: timestamp, Message: parts[1]})
+	}
+	return messages, nil
+}
+
+// parseEvents extracts the events from kernel messages, oldest first
+func parseEvents(messages []kernelMessage, resolver *cgroup.Resolver) []models.KernelEvent {
+	var events []models.KernelEvent
+
+	// The oom-kill summary comes just before the "Killed process" line of the same PID
+	oomCgroups := make(map[int]string)
+
+	for _, message := range messages {
+		text := strings.TrimSpace(message.Message)
+
+		if match := oomKillPattern.FindStringSubmatch(text); match != nil {
+			pid, _ := strconv.Atoi(match[3])
+			oomCgroups[pid] = match[1]
+			continue
+		}
+
+		var event models.KernelEvent
+		if match := oomKilledPattern.FindStringSubmatch(text); match != nil {
+			event.Kind = EventOOMKill
+			event.PID, _ = strconv.Atoi(match[1])
+			event.Process = match[2]
+		} else if match := segfaultPattern.FindStringSubmatch(text); match != nil {
+			event.Kind = EventSegfault
+			event.PID, _ = strconv.Atoi(match[2])
+			event.Process = match[1]
+		} else if match := hungTaskPattern.FindStringSubmatch(text); match != nil {
+			event.Kind = EventHungTask
+			event.PID, _ = strconv.Atoi(match[2])
+			event.Process = match[1]
+		} else {
+			continue
+		}
+		event.Timestamp = message.Timestamp
+		event.Message = text
+
+		// Killed processes are gone, so OOM kills rely on the cgroup the kernel logged. Other
+		// processes may still be running; PIDs can be reused, so only trust a matching name.
+		if cgroupPath, ok := oomCgroups[event.PID]; ok {
+			event.Owner = resolver.Owner(cgroupPath)
+			delete(oomCgroups, event.PID)
+		} else if event.Kind != EventOOMKill && processName(event.PID) == event.Process {
+			event.Owner = resolver.OwnerOfPID(event.PID)
+		}
+
+		events = append(events, event)
+	}
+
+	return events
+}
+
+// processName returns the command name of a running process, or "" if it is gone
+func processName(pid int) string {
+	data, err := ioutil.ReadFile(fmt.Sprintf("/proc/%d/comm", pid))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
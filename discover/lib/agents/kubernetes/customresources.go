@@ -0,0 +1,216 @@
+package kubernetes
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/shellcanary/discover/lib/config"
+	"github.com/shellcanary/discover/lib/models"
+)
+
+// Health values reported for custom resources
+const (
+	HealthReady    = "Ready"
+	HealthNotReady = "NotReady"
+	HealthUnknown  = "Unknown"
+)
+
+// resolvedKind is a configured resource kind mapped to the resource name the API server serves it under
+type resolvedKind struct {
+	kind       models.KubernetesResourceKind
+	resource   string
+	namespaced bool
+}
+
+// GetCustomResources retrieves the configured custom resource kinds across all namespaces in a context
+func GetCustomResources(contextName string) ([]models.KubernetesCustomResource, error) {
+	kinds := resolveResourceKinds(contextName, config.Current().Kubernetes.CustomResources)
+	return listCustomResources(contextName, kinds, "--all-namespaces")
+}
+
+// GetCustomResourcesForNamespace retrieves the configured custom resource kinds in a namespace
+func GetCustomResourcesForNamespace(contextName, namespaceName string) ([]models.KubernetesCustomResource, error) {
+	kinds := resolveResourceKinds(contextName, config.Current().Kubernetes.CustomResources)
+	return listCustomResources(contextName, kinds, "-n", namespaceName)
+}
+
+// resolveResourceKinds uses API discovery to find the resource name for each configured kind,
+// skipping kinds the cluster does not serve
+func resolveResourceKinds(contextName string, kinds []models.KubernetesResourceKind) []resolvedKind {
+	var resolved []resolvedKind
+
+	// Cache discovery documents so kinds sharing a group/version only cost one call
+	discovery := make(map[string][]apiResource)
+	for _, kind := range kinds {
+		groupVersion := kind.Version
+		if kind.Group != "" {
+			groupVersion = kind.Group + "/" + kind.Version
+		}
+
+		resources, cached := discovery[groupVersion]
+		if !cached {
+			var err error
+			resources, err = discoverAPIResources(contextName, groupVersion)
+			if err != nil {
+				fmt.Printf("Warning: %v\n", err)
+			}
+			discovery[groupVersion] = resources
+		}
+
+		found := false
+		for _, resource := range resources {
+			// Subresources such as certificates/status share the kind of their parent
+			if resource.Kind != kind.Kind || strings.Contains(resource.Name, "/") {
+				continue
+			}
+			if !resource.Namespaced {
+				fmt.Printf("Warning: %s in %s is cluster-scoped and will not be listed per namespace\n",
+					kind.Kind, groupVersion)
+			}
+			resolved = append(resolved, resolvedKind{
+				kind:       kind,
+				resource:   resource.Name,
+				namespaced: resource.Namespaced,
+			})
+			found = true
+			break
+		}
+		if !found && resources != nil {
+			fmt.Printf("Warning: Kind %s is not served by %s in context %s\n", kind.Kind, groupVersion, contextName)
+		}
+	}
+
+	return resolved
+}
+
+// apiResource is an entry of an API discovery document
+type apiResource struct {
+	Name       string `json:"name"`
+	Kind       string `json:"kind"`
+	Namespaced bool   `json:"namespaced"`
+}
+
+// discoverAPIResources fetches the discovery document for a group/version
+func discoverAPIResources(contextName, groupVersion string) ([]apiResource, error) {
+	path := "/apis/" + groupVersion
+	if !strings.Contains(groupVersion, "/") {
+		// The core group is served under /api
+		path = "/api/" + groupVersion
+	}
+
+	cmd := exec.Command("kubectl", "get", "--raw", path, "--context", contextName)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("error discovering API resources for %s in context %s: %v", groupVersion, contextName, err)
+	}
+
+	var result struct {
+		Resources []apiResource `json:"resources"`
+	}
+	if err := json.Unmarshal(output, &result); err != nil {
+		return nil, fmt.Errorf("error parsing API discovery JSON for %s: %v", groupVersion, err)
+	}
+
+	return result.Resources, nil
+}
+
+// listCustomResources lists every resolved kind within the given scope and derives each resource's health.
+// A kind that cannot be listed does not stop the others; its error is reported along with what was found.
+func listCustomResources(contextName string, kinds []resolvedKind, scope ...string) ([]models.KubernetesCustomResource, error) {
+	resources := []models.KubernetesCustomResource{}
+	var failures []string
+
+	for _, kind := range kinds {
+		if !kind.namespaced {
+			continue
+		}
+
+		// resource.version.group lets kubectl pick the exact version we were configured with
+		fullName := kind.resource + "." + kind.kind.Version
+		if kind.kind.Group != "" {
+			fullName += "." + kind.kind.Group
+		}
+
+		args := append([]string{"get", fullName, "--context", contextName, "-o", "json"}, scope...)
+		cmd := exec.Command("kubectl", args...)
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("error retrieving %s in context %s: %v", fullName, contextName, err))
+			continue
+		}
+
+		var result struct {
+			Items []struct {
+				Metadata struct {
+					Name      string `json:"name"`
+					Namespace string `json:"namespace"`
+				} `json:"metadata"`
+				Status struct {
+					Conditions []struct {
+						Type    string `json:"type"`
+						Status  string `json:"status"`
+						Reason  string `json:"reason"`
+						Message string `json:"message"`
+					} `json:"conditions"`
+				} `json:"status"`
+			} `json:"items"`
+		}
+		if err := json.Unmarshal(output, &result); err != nil {
+			failures = append(failures, fmt.Sprintf("error parsing %s JSON: %v", fullName, err))
+			continue
+		}
+
+		settings := config.Current().Kubernetes
+		for _, item := range result.Items {
+			if !settings.NamespaceAllowed(item.Metadata.Namespace) {
+				continue
+			}
+
+			var conditions []models.KubernetesCondition
+			for _, condition := range item.Status.Conditions {
+				conditions = append(conditions, models.KubernetesCondition{
+					Type:    condition.Type,
+					Status:  condition.Status,
+					Reason:  condition.Reason,
+					Message: condition.Message,
+				})
+			}
+
+			resources = append(resources, models.KubernetesCustomResource{
+				Name:       item.Metadata.Name,
+				Namespace:  item.Metadata.Namespace,
+				Kind:       kind.kind,
+				Health:     conditionsHealth(conditions),
+				Conditions: conditions,
+			})
+		}
+	}
+
+	if len(failures) > 0 {
+		return resources, fmt.Errorf("%s", strings.Join(failures, "; "))
+	}
+	return resources, nil
+}
+
+// conditionsHealth maps a resource's conditions to Ready/NotReady, using the Ready condition
+// most operators publish and Available as a fallback
+func conditionsHealth(conditions []models.KubernetesCondition) string {
+	for _, conditionType := range []string{"Ready", "Available"} {
+		for _, condition := range conditions {
+			if condition.Type != conditionType {
+				continue
+			}
+			switch condition.Status {
+			case "True":
+				return HealthReady
+			case "False":
+				return HealthNotReady
+			default:
+				return HealthUnknown
+			}
+		}
+	}
+	return HealthUnknown
+}
@@ -6,6 +6,7 @@ import (
 	"strings"
 	"encoding/json"
 
+	"github.com/shellcanary/discover/lib/config"
 	"github.com/shellcanary/discover/lib/models"
 )
 
@@ -28,25 +29,46 @@ func GetKubernetesConfigs() []models.KubernetesConfig {
 
 	contexts := strings.Split(strings.TrimSpace(string(contextsOutput)), "\n")
 	current := strings.TrimSpace(string(currentContext))
+	settings := config.Current().Kubernetes
 
 	for _, context := range contexts {
+		// Skip contexts the user filtered out, so we don't wait on clusters we can't reach
+		if settings.ActiveContextOnly && context != current {
+			continue
+		}
+		if !settings.ContextAllowed(context) {
+			continue
+		}
+
 		status := "Configured"
 		if context == current {
 			status = "Active"
 		}
 
+		// Usage is only available when metrics-server is installed
+		metricsAvailable := MetricsAvailable(context)
+
 		// Get namespaces for this context
-		namespaces, err := GetNamespacesForContext(context)
+		namespaces, err := getNamespacesForContext(context, metricsAvailable)
 		if err != nil {
 			fmt.Printf("Warning: Error getting namespaces for context %s: %v\n", context, err)
 			namespaces = []models.KubernetesNamespace{} // Use empty array instead of nil
 		}
 
+		var nodeUsage []models.KubernetesNodeUsage
+		if metricsAvailable {
+			nodeUsage, err = GetNodeUsage(context)
+			if err != nil {
+				fmt.Printf("Warning: %v\n", err)
+			}
+		}
+
 		configs = append(configs, models.KubernetesConfig{
 			Name:       context,
 			Status:     status,
 			Nodes:      "N/A",
 			Namespaces: namespaces,
+			NodeUsage:  nodeUsage,
 		})
 	}
 
@@ -55,6 +77,12 @@ func GetKubernetesConfigs() []models.KubernetesConfig {
 
 // GetNamespacesForContext retrieves all namespaces in a Kubernetes context
 func GetNamespacesForContext(contextName string) ([]models.KubernetesNamespace, error) {
+	return getNamespacesForContext(contextName, MetricsAvailable(contextName))
+}
+
+// getNamespacesForContext retrieves all namespaces in a Kubernetes context, attaching pod usage
+// when the caller found the metrics API available
+func getNamespacesForContext(contextName string, metricsAvailable bool) ([]models.KubernetesNamespace, error) {
 	cmd := exec.Command("kubectl", "get", "namespaces", "--context", contextName, "-o", "jsonpath={.items[*].metadata.name}")
 	output, err := cmd.CombinedOutput()
 	if err != nil {
@@ -65,6 +93,10 @@ func GetNamespacesForContext(contextName string) ([]models.KubernetesNamespace,
 	if len(namespaceNames) == 0 {
 		return nil, fmt.Errorf("no namespaces found in context %s", contextName)
 	}
+	namespaceNames = filterNamespaces(namespaceNames)
+
+	// Resolve the configured custom resource kinds once for the whole context
+	customKinds := resolveResourceKinds(contextName, config.Current().Kubernetes.CustomResources)
 
 	var namespaces []models.KubernetesNamespace
 	for _, namespaceName := range namespaceNames {
@@ -76,9 +108,53 @@ func GetNamespacesForContext(contextName string) ([]models.KubernetesNamespace,
 			deployments = []models.KubernetesDeployment{} // Use empty array instead of nil
 		}
 
+		// Get services and ingresses so we can tell how traffic reaches each deployment
+		services, err := GetServicesForNamespace(contextName, namespaceName)
+		if err != nil {
+			fmt.Printf("Warning: Error getting services for namespace %s in context %s: %v\n", 
+				namespaceName, contextName, err)
+			services = []models.KubernetesService{}
+		}
+
+		ingresses, err := GetIngressesForNamespace(contextName, namespaceName)
+		if err != nil {
+			fmt.Printf("Warning: Error getting ingresses for namespace %s in context %s: %v\n", 
+				namespaceName, contextName, err)
+			ingresses = []models.KubernetesIngress{}
+		}
+
+		linkDeploymentRoutes(deployments, services, ingresses)
+
+		// Attach current resource usage when metrics-server is installed
+		if metricsAvailable {
+			pods, err := getPodUsage(contextName, namespaceName)
+			if err != nil {
+				fmt.Printf("Warning: %v\n", err)
+			}
+			linkDeploymentUsage(deployments, pods)
+		}
+
+		releases, err := GetHelmReleasesForNamespace(contextName, namespaceName)
+		if err != nil {
+			fmt.Printf("Warning: Error getting Helm releases for namespace %s in context %s: %v\n", 
+				namespaceName, contextName, err)
+			releases = []models.HelmRelease{}
+		}
+		linkHelmReleases(releases, deployments)
+
+		customResources, err := listCustomResources(contextName, customKinds, "-n", namespaceName)
+		if err != nil {
+			fmt.Printf("Warning: Error getting custom resources for namespace %s in context %s: %v\n", 
+				namespaceName, contextName, err)
+		}
+
 		namespaces = append(namespaces, models.KubernetesNamespace{
-			Name:        namespaceName,
-			Deployments: deployments,
+			Name:            namespaceName,
+			Deployments:     deployments,
+			Services:        services,
+			Ingresses:       ingresses,
+			HelmReleases:    releases,
+			CustomResources: customResources,
 		})
 	}
 
@@ -95,14 +171,55 @@ func GetDeploymentsForNamespace(contextName, namespaceName string) ([]models.Kub
 			namespaceName, contextName, err)
 	}
 
+	return parseDeployments(output)
+}
+
+// GetDeploymentsForAllNamespaces retrieves the deployments of every allowed namespace in a context
+func GetDeploymentsForAllNamespaces(contextName string) ([]models.KubernetesDeployment, error) {
+	cmd := exec.Command("kubectl", "get", "deployments", "--all-namespaces", "--context", contextName, "-o", "json")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving deployments for Kubernetes context %s: %v", contextName, err)
+	}
+	
+	deployments, err := parseDeployments(output)
+	if err != nil {
+		return nil, err
+	}
+	
+	settings := config.Current().Kubernetes
+	var allowed []models.KubernetesDeployment
+	for _, deployment := range deployments {
+		if settings.NamespaceAllowed(deployment.Namespace) {
+			allowed = append(allowed, deployment)
+		}
+	}
+	
+	if len(allowed) == 0 {
+		return nil, fmt.Errorf("no deployments found in context %s", contextName)
+	}
+	
+	return allowed, nil
+}
+
+// parseDeployments converts `kubectl get deployments -o json` output into deployment models
+func parseDeployments(output []byte) ([]models.KubernetesDeployment, error) {
 	// Parse the JSON response
 	var result struct {
 		Items []struct {
 			Metadata struct {
-				Name string `json:"name"`
+				Name        string            `json:"name"`
+				Namespace   string            `json:"namespace"`
+				Labels      map[string]string `json:"labels"`
+				Annotations map[string]string `json:"annotations"`
 			} `json:"metadata"`
 			Spec struct {
 				Replicas int `json:"replicas"`
+				Template struct {
+					Metadata struct {
+						Labels map[string]string `json:"labels"`
+					} `json:"metadata"`
+				} `json:"template"`
 			} `json:"spec"`
 			Status struct {
 				AvailableReplicas int `json:"availableReplicas"`
@@ -123,10 +240,13 @@ func GetDeploymentsForNamespace(contextName, namespaceName string) ([]models.Kub
 		}
 
 		deployments = append(deployments, models.KubernetesDeployment{
-			Name:     item.Metadata.Name,
-			Replicas: item.Spec.Replicas,
-			Ready:    item.Status.ReadyReplicas,
-			Status:   status,
+			Name:        item.Metadata.Name,
+			Namespace:   item.Metadata.Namespace,
+			Replicas:    item.Spec.Replicas,
+			Ready:       item.Status.ReadyReplicas,
+			Status:      status,
+			PodLabels:   item.Spec.Template.Metadata.Labels,
+			HelmRelease: helmReleaseName(item.Metadata.Labels, item.Metadata.Annotations),
 		})
 	}
 
@@ -135,13 +255,23 @@ func GetDeploymentsForNamespace(contextName, namespaceName string) ([]models.Kub
 
 // GetKubernetesDeployments retrieves all deployments in a Kubernetes context
 func GetKubernetesDeployments(contextName string) ([]string, error) {
-	cmd := exec.Command("kubectl", "get", "deployments", "--all-namespaces", "--context", contextName, "-o", "jsonpath={.items[*].metadata.name}")
+	cmd := exec.Command("kubectl", "get", "deployments", "--all-namespaces", "--context", contextName, 
+		"-o", "jsonpath={range .items[*]}{.metadata.namespace}{\" \"}{.metadata.name}{\"\\n\"}{end}")
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return nil, fmt.Errorf("error retrieving deployments for Kubernetes context %s: %v", contextName, err)
 	}
 	
-	deployments := strings.Fields(string(output))
+	settings := config.Current().Kubernetes
+	var deployments []string
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		parts := strings.Fields(line)
+		if len(parts) != 2 || !settings.NamespaceAllowed(parts[0]) {
+			continue
+		}
+		deployments = append(deployments, parts[1])
+	}
+	
 	if len(deployments) == 0 {
 		return nil, fmt.Errorf("no deployments found in context %s", contextName)
 	}
@@ -149,27 +279,49 @@ func GetKubernetesDeployments(contextName string) ([]string, error) {
 	return deployments, nil
 }
 
+// filterNamespaces drops the namespaces excluded by the configured patterns
+func filterNamespaces(namespaceNames []string) []string {
+	settings := config.Current().Kubernetes
+	var filtered []string
+	for _, namespaceName := range namespaceNames {
+		if settings.NamespaceAllowed(namespaceName) {
+			filtered = append(filtered, namespaceName)
+		}
+	}
+	return filtered
+}
+
 // GetKubernetesLogs retrieves logs for a specific deployment in a Kubernetes context
 func GetKubernetesLogs(contextName string, deploymentName string) string {
 	// First, find the namespace for this deployment
-	cmd := exec.Command("kubectl", "get", "deployment", "--all-namespaces", "--context", contextName, 
-		"-o", "jsonpath={range .items[?(@.metadata.name==\""+deploymentName+"\")]}{.metadata.namespace}{end}")
-	namespaceOutput, err := cmd.Output()
+	namespace, err := findDeploymentNamespace(contextName, deploymentName)
 	if err != nil {
-		return fmt.Sprintf("Error finding namespace for deployment %s in context %s: %v", deploymentName, contextName, err)
-	}
-	
-	namespace := string(namespaceOutput)
-	if namespace == "" {
-		return fmt.Sprintf("Could not find deployment %s in context %s", deploymentName, contextName)
+		return err.Error()
 	}
 	
 	// Get logs using the namespace
-	cmd = exec.Command("kubectl", "logs", "deployment/"+deploymentName, "-n", namespace, "--context", contextName)
+	cmd := exec.Command("kubectl", "logs", "deployment/"+deploymentName, "-n", namespace, "--context", contextName)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return fmt.Sprintf("Error retrieving logs for deployment %s in namespace %s and context %s: %v", 
 			deploymentName, namespace, contextName, err)
 	}
 	return string(output)
-}
\ No newline at end of file
+}
+
+// findDeploymentNamespace looks up the namespace a deployment lives in within a context
+func findDeploymentNamespace(contextName, deploymentName string) (string, error) {
+	cmd := exec.Command("kubectl", "get", "deployment", "--all-namespaces", "--context", contextName, 
+		"-o", "jsonpath={range .items[?(@.metadata.name==\""+deploymentName+"\")]}{.metadata.namespace}{end}")
+	namespaceOutput, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("Error finding namespace for deployment %s in context %s: %v", deploymentName, contextName, err)
+	}
+	
+	namespace := string(namespaceOutput)
+	if namespace == "" {
+		return "", fmt.Errorf("Could not find deployment %s in context %s", deploymentName, contextName)
+	}
+	
+	return namespace, nil
+}
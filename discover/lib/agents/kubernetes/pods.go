@@ -0,0 +1,44 @@
+package kubernetes
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// PodsByUID maps the UIDs of all pods in a context (the current one if empty) to "namespace/name"
+func PodsByUID(contextName string) (map[string]string, error) {
+	args := []string{"get", "pods", "--all-namespaces", "-o",
+		`jsonpath={range .items[*]}{.metadata.uid}{"|"}{.metadata.namespace}{"/"}{.metadata.name}{"\n"}{end}`}
+	if contextName != "" {
+		args = append(args, "--context", contextName)
+	}
+
+	output, err := exec.Command("kubectl", args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("error listing pods: %v", err)
+	}
+
+	pods := make(map[string]string)
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		parts := strings.SplitN(line, "|", 2)
+		if len(parts) == 2 {
+			pods[parts[0]] = parts[1]
+		}
+	}
+	return pods, nil
+}
+
+// GetPodLogs retrieves the recent logs of a pod in a context (the current one if empty)
+func GetPodLogs(contextName, namespace, podName string) string {
+	args := []string{"logs", podName, "-n", namespace, "--all-containers", "--tail", "100"}
+	if contextName != "" {
+		args = append(args, "--context", contextName)
+	}
+
+	output, err := exec.Command("kubectl", args...).CombinedOutput()
+	if err != nil {
+		return fmt.Sprintf("Error retrieving logs for pod %s in namespace %s: %v", podName, namespace, err)
+	}
+	return string(output)
+}
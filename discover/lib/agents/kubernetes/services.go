@@ -0,0 +1,352 @@
+package kubernetes
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strconv"
+
+	"github.com/shellcanary/discover/lib/models"
+)
+
+// GetServicesForNamespace retrieves all services in a namespace along with their endpoint readiness
+func GetServicesForNamespace(contextName, namespaceName string) ([]models.KubernetesService, error) {
+	cmd := exec.Command("kubectl", "get", "services", "-n", namespaceName, "--context", contextName, "-o", "json")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving services for namespace %s in context %s: %v",
+			namespaceName, contextName, err)
+	}
+
+	var result struct {
+		Items []struct {
+			Metadata struct {
+				Name string `json:"name"`
+			} `json:"metadata"`
+			Spec struct {
+				Type      string            `json:"type"`
+				ClusterIP string            `json:"clusterIP"`
+				Selector  map[string]string `json:"selector"`
+				Ports     []struct {
+					Name       string          `json:"name"`
+					Protocol   string          `json:"protocol"`
+					Port       int             `json:"port"`
+					TargetPort json.RawMessage `json:"targetPort"`
+					NodePort   int             `json:"nodePort"`
+				} `json:"ports"`
+			} `json:"spec"`
+		} `json:"items"`
+	}
+
+	if err := json.Unmarshal(output, &result); err != nil {
+		return nil, fmt.Errorf("error parsing services JSON: %v", err)
+	}
+
+	// Endpoints are fetched in one call and matched to services by name
+	endpoints, err := getEndpointsForNamespace(contextName, namespaceName)
+	if err != nil {
+		fmt.Printf("Warning: %v\n", err)
+		endpoints = map[string]endpointAddresses{}
+	}
+
+	var services []models.KubernetesService
+	for _, item := range result.Items {
+		var ports []models.KubernetesServicePort
+		for _, port := range item.Spec.Ports {
+			ports = append(ports, models.KubernetesServicePort{
+				Name:       port.Name,
+				Protocol:   port.Protocol,
+				Port:       port.Port,
+				TargetPort: intOrString(port.TargetPort),
+				NodePort:   port.NodePort,
+			})
+		}
+
+		addresses := endpoints[item.Metadata.Name]
+		services = append(services, models.KubernetesService{
+			Name:              item.Metadata.Name,
+			Type:              item.Spec.Type,
+			ClusterIP:         item.Spec.ClusterIP,
+			Ports:             ports,
+			Selector:          item.Spec.Selector,
+			ReadyEndpoints:    addresses.ready,
+			NotReadyEndpoints: addresses.notReady,
+		})
+	}
+
+	return services, nil
+}
+
+// endpointAddresses holds the ready and not-ready addresses behind a service
+type endpointAddresses struct {
+	ready    []string
+	notReady []string
+}
+
+// getEndpointsForNamespace returns the ready and not-ready endpoint addresses keyed by service name
+func getEndpointsForNamespace(contextName, namespaceName string) (map[string]endpointAddresses, error) {
+	cmd := exec.Command("kubectl", "get", "endpoints", "-n", namespaceName, "--context", contextName, "-o", "json")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving endpoints for namespace %s in context %s: %v",
+			namespaceName, contextName, err)
+	}
+
+	type address struct {
+		IP        string `json:"ip"`
+		TargetRef struct {
+			Name string `json:"name"`
+		} `json:"targetRef"`
+	}
+	var result struct {
+		Items []struct {
+			Metadata struct {
+				Name string `json:"name"`
+			} `json:"metadata"`
+			Subsets []struct {
+				Addresses         []address `json:"addresses"`
+				NotReadyAddresses []address `json:"notReadyAddresses"`
+				Ports             []struct {
+					Port int `json:"port"`
+				} `json:"ports"`
+			} `json:"subsets"`
+		} `json:"items"`
+	}
+
+	if err := json.Unmarshal(output, &result); err != nil {
+		return nil, fmt.Errorf("error parsing endpoints JSON: %v", err)
+	}
+
+	// Format each address as ip:port (pod), one entry per port
+	format := func(addr address, port int) string {
+		endpoint := addr.IP
+		if port != 0 {
+			endpoint = fmt.Sprintf("%s:%d", addr.IP, port)
+		}
+		if addr.TargetRef.Name != "" {
+			endpoint = fmt.Sprintf("%s (%s)", endpoint, addr.TargetRef.Name)
+		}
+		return endpoint
+	}
+
+	endpoints := make(map[string]endpointAddresses)
+	for _, item := range result.Items {
+		var ready, notReady []string
+		for _, subset := range item.Subsets {
+			ports := []int{0}
+			if len(subset.Ports) > 0 {
+				ports = ports[:0]
+				for _, port := range subset.Ports {
+					ports = append(ports, port.Port)
+				}
+			}
+			for _, port := range ports {
+				for _, addr := range subset.Addresses {
+					ready = append(ready, format(addr, port))
+				}
+				for _, addr := range subset.NotReadyAddresses {
+					notReady = append(notReady, format(addr, port))
+				}
+			}
+		}
+		endpoints[item.Metadata.Name] = endpointAddresses{ready: ready, notReady: notReady}
+	}
+
+	return endpoints, nil
+}
+
+// GetIngressesForNamespace retrieves all ingresses in a namespace with their host/path rules
+func GetIngressesForNamespace(contextName, namespaceName string) ([]models.KubernetesIngress, error) {
+	cmd := exec.Command("kubectl", "get", "ingresses", "-n", namespaceName, "--context", contextName, "-o", "json")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving ingresses for namespace %s in context %s: %v",
+			namespaceName, contextName, err)
+	}
+
+	type backend struct {
+		Service struct {
+			Name string `json:"name"`
+			Port struct {
+				Name   string `json:"name"`
+				Number int    `json:"number"`
+			} `json:"port"`
+		} `json:"service"`
+	}
+	var result struct {
+		Items []struct {
+			Metadata struct {
+				Name string `json:"name"`
+			} `json:"metadata"`
+			Spec struct {
+				IngressClassName string   `json:"ingressClassName"`
+				DefaultBackend   *backend `json:"defaultBackend"`
+				Rules            []struct {
+					Host string `json:"host"`
+					HTTP struct {
+						Paths []struct {
+							Path    string  `json:"path"`
+							Backend backend `json:"backend"`
+						} `json:"paths"`
+					} `json:"http"`
+				} `json:"rules"`
+			} `json:"spec"`
+		} `json:"items"`
+	}
+
+	if err := json.Unmarshal(output, &result); err != nil {
+		return nil, fmt.Errorf("error parsing ingresses JSON: %v", err)
+	}
+
+	servicePort := func(b backend) string {
+		if b.Service.Port.Name != "" {
+			return b.Service.Port.Name
+		}
+		return strconv.Itoa(b.Service.Port.Number)
+	}
+
+	var ingresses []models.KubernetesIngress
+	for _, item := range result.Items {
+		var rules []models.KubernetesIngressRule
+		if item.Spec.DefaultBackend != nil && item.Spec.DefaultBackend.Service.Name != "" {
+			rules = append(rules, models.KubernetesIngressRule{
+				Host:        "*",
+				Path:        "/",
+				ServiceName: item.Spec.DefaultBackend.Service.Name,
+				ServicePort: servicePort(*item.Spec.DefaultBackend),
+			})
+		}
+		for _, rule := range item.Spec.Rules {
+			host := rule.Host
+			if host == "" {
+				host = "*"
+			}
+			for _, path := range rule.HTTP.Paths {
+				rules = append(rules, models.KubernetesIngressRule{
+					Host:        host,
+					Path:        path.Path,
+					ServiceName: path.Backend.Service.Name,
+					ServicePort: servicePort(path.Backend),
+				})
+			}
+		}
+
+		ingresses = append(ingresses, models.KubernetesIngress{
+			Name:      item.Metadata.Name,
+			ClassName: item.Spec.IngressClassName,
+			Rules:     rules,
+		})
+	}
+
+	return ingresses, nil
+}
+
+// GetDeploymentRoutes returns the services and ingresses that route traffic to a deployment
+func GetDeploymentRoutes(contextName, deploymentName string) ([]models.KubernetesService, []models.KubernetesIngress, error) {
+	namespace, err := findDeploymentNamespace(contextName, deploymentName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	deployments, err := GetDeploymentsForNamespace(contextName, namespace)
+	if err != nil {
+		return nil, nil, err
+	}
+	services, err := GetServicesForNamespace(contextName, namespace)
+	if err != nil {
+		return nil, nil, err
+	}
+	ingresses, err := GetIngressesForNamespace(contextName, namespace)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	linkDeploymentRoutes(deployments, services, ingresses)
+
+	for _, deployment := range deployments {
+		if deployment.Name != deploymentName {
+			continue
+		}
+
+		var routedServices []models.KubernetesService
+		for _, service := range services {
+			if contains(deployment.Services, service.Name) {
+				routedServices = append(routedServices, service)
+			}
+		}
+		var routedIngresses []models.KubernetesIngress
+		for _, ingress := range ingresses {
+			if contains(deployment.Ingresses, ingress.Name) {
+				routedIngresses = append(routedIngresses, ingress)
+			}
+		}
+		return routedServices, routedIngresses, nil
+	}
+
+	return nil, nil, fmt.Errorf("could not find deployment %s in namespace %s", deploymentName, namespace)
+}
+
+// linkDeploymentRoutes fills in the services selecting each deployment's pods and the ingresses
+// that send traffic to those services
+func linkDeploymentRoutes(deployments []models.KubernetesDeployment, services []models.KubernetesService, ingresses []models.KubernetesIngress) {
+	for i := range deployments {
+		deployment := &deployments[i]
+		deployment.Services = nil
+		deployment.Ingresses = nil
+
+		for _, service := range services {
+			if selectorMatches(service.Selector, deployment.PodLabels) {
+				deployment.Services = append(deployment.Services, service.Name)
+			}
+		}
+
+		for _, ingress := range ingresses {
+			for _, rule := range ingress.Rules {
+				if contains(deployment.Services, rule.ServiceName) {
+					deployment.Ingresses = append(deployment.Ingresses, ingress.Name)
+					break
+				}
+			}
+		}
+
+		sort.Strings(deployment.Services)
+		sort.Strings(deployment.Ingresses)
+	}
+}
+
+// selectorMatches reports whether every key/value in the selector is present in the labels.
+// An empty selector matches nothing, since such services have manually managed endpoints.
+func selectorMatches(selector, labels map[string]string) bool {
+	if len(selector) == 0 {
+		return false
+	}
+	for key, value := range selector {
+		if labels[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// intOrString renders a Kubernetes IntOrString JSON value as a string
+func intOrString(raw json.RawMessage) string {
+	var number int
+	if err := json.Unmarshal(raw, &number); err == nil {
+		return strconv.Itoa(number)
+	}
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return text
+	}
+	return ""
+}
+
+func contains(items []string, item string) bool {
+	for _, candidate := range items {
+		if candidate == item {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,153 @@
+package supervisor
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shellcanary/discover/lib/models"
+)
+
+const (
+	// Manager identifies supervisord programs in ManagedProgram.Manager
+	Manager = "supervisord"
+	// logTailBytes is how much of a program log is fetched
+	logTailBytes = 16384
+	// mainLogTailBytes is how much of supervisord's own log is searched for restarts
+	mainLogTailBytes = 262144
+)
+
+var (
+	// uptimePattern matches the description of a running program, e.g. "pid 1234, uptime 2 days, 3:04:05"
+	uptimePattern = regexp.MustCompile(`pid (\d+), uptime (?:(\d+) days?, )?(\d+):(\d+):(\d+)`)
+	// spawnedPattern matches supervisord's log line for each start of a process
+	spawnedPattern = regexp.MustCompile(`spawned: '([^']+)' with pid`)
+)
+
+// GetSupervisorPrograms returns the programs of supervisord with their state, PID, uptime and the
+// restarts found in supervisord's recent log
+func GetSupervisorPrograms() ([]models.ManagedProgram, error) {
+	// supervisorctl exits non-zero when any program is not running, so the output decides
+	output, err := exec.Command("supervisorctl", "status").CombinedOutput()
+	programs, parsed := parseStatus(string(output))
+	if !parsed {
+		if err == nil {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error reading supervisord status: %v\nOutput: %s", err, strings.TrimSpace(string(output)))
+	}
+
+	restarts := restartCounts(programs)
+	for i := range programs {
+		programs[i].Restarts = restarts[programs[i].Name]
+	}
+	return programs, nil
+}
+
+// parseStatus parses `supervisorctl status` lines such as
+// "web:web_00   RUNNING   pid 1234, uptime 0:10:00". It reports false when no line parsed,
+// e.g. when supervisord is not running.
+func parseStatus(output string) ([]models.ManagedProgram, bool) {
+	var programs []models.ManagedProgram
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || !isState(fields[1]) {
+			continue
+		}
+
+		program := models.ManagedProgram{
+			Manager: Manager,
+			Name:    fields[0],
+			State:   fields[1],
+		}
+		description := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), fields[0]))
+		description = strings.TrimSpace(strings.TrimPrefix(description, fields[1]))
+
+		if match := uptimePattern.FindStringSubmatch(description); match != nil {
+			program.PID, _ = strconv.Atoi(match[1])
+			days, _ := strconv.Atoi(match[2])
+			hours, _ := strconv.Atoi(match[3])
+			minutes, _ := strconv.Atoi(match[4])
+			seconds, _ := strconv.Atoi(match[5])
+			program.Uptime = time.Duration(days)*24*time.Hour + time.Duration(hours)*time.Hour +
+				time.Duration(minutes)*time.Minute + time.Duration(seconds)*time.Second
+		} else {
+			program.Description = description
+		}
+		programs = append(programs, program)
+	}
+	return programs, len(programs) > 0
+}
+
+// isState reports whether a word is a supervisord process state
+func isState(word string) bool {
+	switch word {
+	case "STOPPED", "STARTING", "RUNNING", "BACKOFF", "STOPPING", "EXITED", "FATAL", "UNKNOWN":
+		return true
+	}
+	return false
+}
+
+// processName returns the process name of "group:process"; supervisord logs processes by it
+func processName(name string) string {
+	if colon := strings.LastIndex(name, ":"); colon != -1 {
+		return name[colon+1:]
+	}
+	return name
+}
+
+// restartCounts counts how often each program, keyed by its full "group:process" name, was
+// restarted in the recent supervisord log: every spawn after the first is a restart. supervisord
+// logs spawns by process name only, so processes sharing a name across groups (e.g. web:worker
+// and jobs:worker) cannot be told apart and get no count.
+func restartCounts(programs []models.ManagedProgram) map[string]int {
+	counts := make(map[string]int)
+	output, err := exec.Command("supervisorctl", "maintail", "-"+strconv.Itoa(mainLogTailBytes)).Output()
+	if err != nil {
+		return counts
+	}
+
+	spawns := make(map[string]int)
+	for _, match := range spawnedPattern.FindAllStringSubmatch(string(output), -1) {
+		spawns[match[1]]++
+	}
+
+	owners := make(map[string][]string)
+	for _, program := range programs {
+		process := processName(program.Name)
+		owners[process] = append(owners[process], program.Name)
+	}
+	for process, names := range owners {
+		if len(names) == 1 && spawns[process] > 1 {
+			counts[names[0]] = spawns[process] - 1
+		}
+	}
+	return counts
+}
+
+// GetProgramLogs returns the end of a program's stdout log, or its stderr log when stderr is true
+func GetProgramLogs(name string, stderr bool) string {
+	stream := "stdout"
+	if stderr {
+		stream = "stderr"
+	}
+
+	output, err := exec.Command("supervisorctl", "tail", "-"+strconv.Itoa(logTailBytes), name, stream).CombinedOutput()
+	if err != nil {
+		return fmt.Sprintf("Error retrieving %s logs for program %s: %v\n%s", stream, name, err, string(output))
+	}
+	return string(output)
+}
+
+// RestartProgram restarts a supervisord program
+func RestartProgram(name string) error {
+	output, err := exec.Command("supervisorctl", "restart", name).CombinedOutput()
+	// Older supervisorctl versions exit 0 even when the restart failed
+	if err != nil || strings.Contains(string(output), "ERROR") {
+		return fmt.Errorf("error restarting program %s: %v\nOutput: %s", name, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+package systemd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shellcanary/discover/lib/models"
+)
+
+// bootIDFile holds the ID of the current boot
+const bootIDFile = "/proc/sys/kernel/random/boot_id"
+
+// bootPhasePattern matches the phases of `systemd-analyze time`, e.g. "1.823s (kernel)"
+var bootPhasePattern = regexp.MustCompile(`([0-9][0-9a-zµ. ]*?) \((firmware|loader|kernel|initrd|userspace)\)`)
+
+// bootTotalPattern matches the total of `systemd-analyze time`, e.g. "= 19.305s"
+var bootTotalPattern = regexp.MustCompile(`= ([0-9][0-9a-zµ. ]*[a-zµ])`)
+
+// timespanPattern matches one component of a systemd timespan, e.g. "1min" or "2.345s"
+var timespanPattern = regexp.MustCompile(`([0-9]+(?:\.[0-9]+)?)(us|µs|ms|s|min|h|d)\b`)
+
+// GetBootAnalysis captures the boot time, the per-unit activation times and the critical chain
+// of the current boot
+func GetBootAnalysis() (models.BootAnalysis, error) {
+	analysis := models.BootAnalysis{
+		BootID:   CurrentBootID(),
+		Captured: time.Now(),
+	}
+
+	output, err := analyzeCommand("time").CombinedOutput()
+	if err != nil {
+		// Fails while the boot is still in progress, or when systemd is not the init system
+		return analysis, fmt.Errorf("error reading boot time: %v\nOutput: %s", err, strings.TrimSpace(string(output)))
+	}
+	parseBootTime(string(output), &analysis)
+
+	output, err = analyzeCommand("blame").Output()
+	if err != nil {
+		fmt.Printf("Warning: Failed to read unit activation times: %v\n", err)
+	} else {
+		analysis.Blame = parseBlame(string(output))
+	}
+
+	output, err = analyzeCommand("critical-chain").Output()
+	if err != nil {
+		fmt.Printf("Warning: Failed to read the critical chain: %v\n", err)
+	} else {
+		analysis.CriticalChain = parseCriticalChain(string(output))
+	}
+
+	return analysis, nil
+}
+
+// CurrentBootID returns the ID of the current boot in the format the journal uses
+func CurrentBootID() string {
+	data, err := ioutil.ReadFile(bootIDFile)
+	if err != nil {
+		return ""
+	}
+	return strings.ReplaceAll(strings.TrimSpace(string(data)), "-", "")
+}
+
+// CompareBoots compares the unit activation times of two boots, returning the units present
+// in both ordered by how much slower they got
+func CompareBoots(previous, current models.BootAnalysis) []models.BootUnitChange {
+	before := make(map[string]time.Duration)
+	for _, timing := range previous.Blame {
+		before[timing.Unit] = timing.Time
+	}
+
+	var changes []models.BootUnitChange
+	for _, timing := range current.Blame {
+		if previousTime, ok := before[timing.Unit]; ok {
+			changes = append(changes, models.BootUnitChange{
+				Unit:     timing.Unit,
+				Previous: previousTime,
+				Current:  timing.Time,
+			})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Current-changes[i].Previous > changes[j].Current-changes[j].Previous
+	})
+	return changes
+}
+
+// analyzeCommand builds a systemd-analyze command with output that parses the same everywhere
+func analyzeCommand(args ...string) *exec.Cmd {
+	cmd := exec.Command("systemd-analyze", append(args, "--no-pager")...)
+	cmd.Env = append(os.Environ(), "LC_ALL=C")
+	return cmd
+}
+
+// parseBootTime reads the phase times and total from `systemd-analyze time`
+func parseBootTime(output string, analysis *models.BootAnalysis) {
+	// Only the first line holds the times; the second names the target that was reached
+	line := strings.SplitN(output, "\n", 2)[0]
+
+	for _, match := range bootPhasePattern.FindAllStringSubmatch(line, -1) {
+		duration := parseTimespan(match[1])
+		switch match[2] {
+		case "firmware":
+			analysis.Firmware = duration
+		case "loader":
+			analysis.Loader = duration
+		case "kernel":
+			analysis.Kernel = duration
+		case "initrd":
+			analysis.Initrd = duration
+		case "userspace":
+			analysis.Userspace = duration
+		}
+	}
+
+	if match := bootTotalPattern.FindStringSubmatch(line); match != nil {
+		analysis.Total = parseTimespan(match[1])
+	}
+}
+
+// parseBlame reads `systemd-analyze blame` lines such as "1min 2.345s docker.service"
+func parseBlame(output string) []models.UnitTiming {
+	var timings []models.UnitTiming
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		unit := fields[len(fields)-1]
+		timings = append(timings, models.UnitTiming{
+			Unit: unit,
+			Time: parseTimespan(strings.Join(fields[:len(fields)-1], " ")),
+		})
+	}
+	return timings
+}
+
+// parseCriticalChain reads the `systemd-analyze critical-chain` tree, whose lines look like
+// "└─nginx.service @8.104s +2.001s"
+func parseCriticalChain(output string) []models.CriticalChainUnit {
+	var chain []models.CriticalChainUnit
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimLeft(line, " └├─│")
+		if line == "" || !strings.ContainsAny(line, "@+") {
+			// Header lines explain the notation
+			continue
+		}
+
+		fields := strings.SplitN(line, " ", 2)
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "The") {
+			continue
+		}
+
+		unit := models.CriticalChainUnit{Unit: fields[0]}
+		rest := fields[1]
+		if plus := strings.Index(rest, "+"); plus != -1 {
+			unit.Took = parseTimespan(rest[plus+1:])
+			rest = rest[:plus]
+		}
+		if at := strings.Index(rest, "@"); at != -1 {
+			unit.ActiveAt = parseTimespan(rest[at+1:])
+		}
+		chain = append(chain, unit)
+	}
+	return chain
+}
+
+// parseTimespan parses a systemd timespan such as "1min 2.345s" or "345ms"
+func parseTimespan(text string) time.Duration {
+	units := map[string]time.Duration{
+		"us": time.Microsecond, "µs": time.Microsecond, "ms": time.Millisecond,
+		"s": time.Second, "min": time.Minute, "h": time.Hour, "d": 24 * time.Hour,
+	}
+
+	var total time.Duration
+	for _, match := range timespanPattern.FindAllStringSubmatch(text, -1) {
+		value, err := strconv.ParseFloat(match[1], 64)
+		if err != nil {
+			continue
+		}
+		total += time.Duration(math.Round(value * float64(units[match[2]])))
+	}
+	return total
+}
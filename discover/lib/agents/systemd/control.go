@@ -0,0 +1,208 @@
+package systemd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/shellcanary/discover/lib/models"
+	"github.com/shellcanary/discover/lib/sessions"
+)
+
+// Control actions supported by ControlService
+const (
+	ActionStart        = "start"
+	ActionStop         = "stop"
+	ActionRestart      = "restart"
+	ActionReload       = "reload"
+	ActionEnable       = "enable"
+	ActionDisable      = "disable"
+	ActionMask         = "mask"
+	ActionUnmask       = "unmask"
+	ActionDaemonReload = "daemon-reload"
+)
+
+// Ways to retry an action the manager refused without authentication
+const (
+	// EscalatePolkit runs systemctl on the terminal so polkit can ask for a password
+	EscalatePolkit = "polkit"
+	// EscalateSudo runs systemctl through sudo
+	EscalateSudo = "sudo"
+)
+
+// actionSettleTimeout bounds how long an action waits for the job it queued over D-Bus to finish
+const actionSettleTimeout = 10 * time.Second
+
+// actionCheckProperties are the properties read to check the outcome of an action
+var actionCheckProperties = []string{"ActiveState", "SubState", "UnitFileState", "NeedDaemonReload"}
+
+// AuthorizationError is returned when the manager refused an action because the user is not
+// authorized to perform it without authenticating
+type AuthorizationError struct {
+	Unit   string
+	Action string
+	Output string
+}
+
+func (e *AuthorizationError) Error() string {
+	return fmt.Sprintf("not authorized to %s %s: %s", e.Action, e.Unit, e.Output)
+}
+
+// IsAuthorizationError reports whether an action failed only because authentication is required
+func IsAuthorizationError(err error) bool {
+	_, ok := err.(*AuthorizationError)
+	return ok
+}
+
+// ControlService performs a control action on a service through the manager it belongs to and
+// reports whether the unit reached the state the action should lead to. Nothing prompts for a
+// password: if the manager refuses, an *AuthorizationError is returned and the action can be
+// retried with ControlServiceEscalated.
+func ControlService(service models.SystemdService, action string) (models.SystemdActionResult, error) {
+	serviceName := serviceUnitName(service.Name)
+	target := targetFor(service)
+	if expectedState(action) == "" {
+		return models.SystemdActionResult{}, fmt.Errorf("unknown action %s", action)
+	}
+
+	// D-Bus needs the caller to be authorized up front, so fall back to systemctl on any failure;
+	// it reports authorization failures in a form we can recognize
+	if err := controlUnitDBus(target, action, serviceName); err != nil {
+		args := append([]string{"--no-ask-password"}, actionArgs(action, serviceName)...)
+		output, err := systemctlCommand(target, args...).CombinedOutput()
+		if err != nil {
+			if isAuthorizationFailure(string(output)) {
+				return models.SystemdActionResult{}, &AuthorizationError{
+					Unit:   serviceName,
+					Action: action,
+					Output: strings.TrimSpace(string(output)),
+				}
+			}
+			return models.SystemdActionResult{}, fmt.Errorf("failed to %s service %s: %v\nOutput: %s",
+				action, serviceName, err, string(output))
+		}
+	}
+
+	return checkActionResult(target, serviceName, action), nil
+}
+
+// ControlServiceEscalated retries an action with the given escalation method, handing the
+// terminal to systemctl (or sudo) so the user can authenticate
+func ControlServiceEscalated(service models.SystemdService, action, method string) (models.SystemdActionResult, error) {
+	serviceName := serviceUnitName(service.Name)
+	target := targetFor(service)
+	if expectedState(action) == "" {
+		return models.SystemdActionResult{}, fmt.Errorf("unknown action %s", action)
+	}
+
+	cmd := systemctlCommand(target, actionArgs(action, serviceName)...)
+	switch method {
+	case EscalatePolkit:
+		// systemctl starts a polkit text agent itself when it runs on a terminal
+	case EscalateSudo:
+		// sudo would switch to root's own user manager, so it only makes sense for system units
+		if target.scope != ScopeSystem {
+			return models.SystemdActionResult{}, fmt.Errorf("sudo escalation only applies to system services")
+		}
+		cmd = exec.Command("sudo", cmd.Args...)
+		cmd.Env = append(os.Environ(), "LC_ALL=C")
+	default:
+		return models.SystemdActionResult{}, fmt.Errorf("unknown escalation method %s", method)
+	}
+
+	if err := sessions.RunInteractive(cmd); err != nil {
+		return models.SystemdActionResult{}, fmt.Errorf("failed to %s service %s with %s: %v",
+			action, serviceName, method, err)
+	}
+
+	return checkActionResult(target, serviceName, action), nil
+}
+
+// SudoAvailable reports whether sudo is installed, for offering it as an escalation method
+func SudoAvailable() bool {
+	_, err := exec.LookPath("sudo")
+	return err == nil
+}
+
+// actionArgs returns the systemctl arguments for an action
+func actionArgs(action, unitName string) []string {
+	if action == ActionDaemonReload {
+		return []string{action}
+	}
+	return []string{action, unitName}
+}
+
+// isAuthorizationFailure recognizes polkit and D-Bus access errors in systemctl output
+func isAuthorizationFailure(output string) bool {
+	output = strings.ToLower(output)
+	return strings.Contains(output, "access denied") ||
+		strings.Contains(output, "authentication required") ||
+		strings.Contains(output, "authentication is required") ||
+		strings.Contains(output, "not authorized")
+}
+
+// expectedState describes the state an action should lead to, or "" for unknown actions
+func expectedState(action string) string {
+	switch action {
+	case ActionStart, ActionRestart, ActionReload:
+		return "active"
+	case ActionStop:
+		return "inactive"
+	case ActionEnable:
+		return "enabled"
+	case ActionDisable:
+		return "disabled"
+	case ActionMask:
+		return "masked"
+	case ActionUnmask:
+		return "not masked"
+	case ActionDaemonReload:
+		return "unit files reloaded"
+	}
+	return ""
+}
+
+// reachedState checks the unit properties against the state expected after an action
+func reachedState(action string, properties map[string]string) bool {
+	switch action {
+	case ActionStart, ActionRestart, ActionReload:
+		return properties["ActiveState"] == "active"
+	case ActionStop:
+		return properties["ActiveState"] == "inactive"
+	case ActionEnable:
+		// Also covers enabled-runtime
+		return strings.HasPrefix(properties["UnitFileState"], "enabled")
+	case ActionDisable:
+		return properties["UnitFileState"] == "disabled"
+	case ActionMask:
+		return strings.HasPrefix(properties["UnitFileState"], "masked")
+	case ActionUnmask:
+		return !strings.HasPrefix(properties["UnitFileState"], "masked")
+	case ActionDaemonReload:
+		return properties["NeedDaemonReload"] == "no"
+	}
+	return false
+}
+
+// checkActionResult reads the unit state after an action has completed
+func checkActionResult(target managerTarget, unitName, action string) models.SystemdActionResult {
+	result := models.SystemdActionResult{
+		Unit:     unitName,
+		Action:   action,
+		Expected: expectedState(action),
+	}
+
+	properties, err := unitProperties(target, unitName, actionCheckProperties)
+	if err != nil {
+		fmt.Printf("Warning: Failed to check the state of %s: %v\n", unitName, err)
+		return result
+	}
+
+	result.ActiveState = properties["ActiveState"]
+	result.SubState = properties["SubState"]
+	result.UnitFileState = properties["UnitFileState"]
+	result.Reached = reachedState(action, properties)
+	return result
+}
@@ -0,0 +1,204 @@
+package systemd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	systemdBusName      = "org.freedesktop.systemd1"
+	systemdObjectPath   = dbus.ObjectPath("/org/freedesktop/systemd1")
+	managerInterface    = "org.freedesktop.systemd1.Manager"
+	unitInterface       = "org.freedesktop.systemd1.Unit"
+	jobInterface        = "org.freedesktop.systemd1.Job"
+	propertiesGetAll    = "org.freedesktop.DBus.Properties.GetAll"
+	unitInterfacePrefix = "org.freedesktop.systemd1."
+)
+
+// connectSystemBus returns the connection used to talk to the system manager.
+// It is a variable so a private bus exporting a stand-in manager can be used instead.
+var connectSystemBus = dbus.SystemBus
+
+// dbusUnit mirrors the (ssssssouso) struct returned by Manager.ListUnits
+type dbusUnit struct {
+	Name        string
+	Description string
+	LoadState   string
+	ActiveState string
+	SubState    string
+	Followed    string
+	Path        dbus.ObjectPath
+	JobID       uint32
+	JobType     string
+	JobPath     dbus.ObjectPath
+}
+
+// listUnitsDBus lists the loaded units with the given suffix (e.g. ".service") via Manager.ListUnits
+func listUnitsDBus(target managerTarget, suffix string) ([]unitStatus, error) {
+	conn, err := target.bus()
+	if err != nil {
+		return nil, err
+	}
+
+	var units []dbusUnit
+	manager := conn.Object(systemdBusName, systemdObjectPath)
+	if err := manager.Call(managerInterface+".ListUnits", 0).Store(&units); err != nil {
+		return nil, fmt.Errorf("error listing units over D-Bus: %v", err)
+	}
+
+	var statuses []unitStatus
+	for _, unit := range units {
+		if !strings.HasSuffix(unit.Name, suffix) {
+			continue
+		}
+		statuses = append(statuses, unitStatus{
+			Name:        unit.Name,
+			Description: unit.Description,
+			LoadState:   unit.LoadState,
+			ActiveState: unit.ActiveState,
+			SubState:    unit.SubState,
+		})
+	}
+
+	return statuses, nil
+}
+
+// unitPropertiesDBus reads the Unit properties plus those of the unit's type-specific interface
+// (e.g. org.freedesktop.systemd1.Service) and renders them like `systemctl show` would
+func unitPropertiesDBus(target managerTarget, unitName string) (map[string]string, error) {
+	conn, err := target.bus()
+	if err != nil {
+		return nil, err
+	}
+
+	// LoadUnit returns the object path even for units that are not currently loaded
+	var path dbus.ObjectPath
+	manager := conn.Object(systemdBusName, systemdObjectPath)
+	if err := manager.Call(managerInterface+".LoadUnit", 0, unitName).Store(&path); err != nil {
+		return nil, fmt.Errorf("error loading unit %s over D-Bus: %v", unitName, err)
+	}
+
+	interfaces := []string{unitInterface}
+	if dot := strings.LastIndex(unitName, "."); dot != -1 {
+		unitType := unitName[dot+1:]
+		interfaces = append(interfaces, unitInterfacePrefix+strings.ToUpper(unitType[:1])+unitType[1:])
+	}
+
+	properties := make(map[string]string)
+	unit := conn.Object(systemdBusName, path)
+	for _, iface := range interfaces {
+		var values map[string]dbus.Variant
+		if err := unit.Call(propertiesGetAll, 0, iface).Store(&values); err != nil {
+			return nil, fmt.Errorf("error reading %s properties of %s over D-Bus: %v", iface, unitName, err)
+		}
+		for name, value := range values {
+			properties[name] = formatVariant(value)
+		}
+	}
+
+	return properties, nil
+}
+
+// managerJobMethods maps the actions that queue a job to the Manager method that does so
+var managerJobMethods = map[string]string{
+	ActionStart:   "StartUnit",
+	ActionStop:    "StopUnit",
+	ActionRestart: "RestartUnit",
+	ActionReload:  "ReloadUnit",
+}
+
+// controlUnitDBus performs a control action over D-Bus. Job actions replace any queued job for the
+// unit and return once the job is queued; unit file actions are followed by a manager reload like
+// systemctl does.
+func controlUnitDBus(target managerTarget, action, unitName string) error {
+	conn, err := target.bus()
+	if err != nil {
+		return err
+	}
+
+	manager := conn.Object(systemdBusName, systemdObjectPath)
+	if method, ok := managerJobMethods[action]; ok {
+		var job dbus.ObjectPath
+		if err := manager.Call(managerInterface+"."+method, 0, unitName, "replace").Store(&job); err != nil {
+			return fmt.Errorf("error running %s on unit %s over D-Bus: %v", action, unitName, err)
+		}
+		waitForJob(conn, job)
+		return nil
+	}
+
+	var call *dbus.Call
+	files := []string{unitName}
+	switch action {
+	case ActionEnable:
+		call = manager.Call(managerInterface+".EnableUnitFiles", 0, files, false, false)
+	case ActionDisable:
+		call = manager.Call(managerInterface+".DisableUnitFiles", 0, files, false)
+	case ActionMask:
+		call = manager.Call(managerInterface+".MaskUnitFiles", 0, files, false, false)
+	case ActionUnmask:
+		call = manager.Call(managerInterface+".UnmaskUnitFiles", 0, files, false)
+	case ActionDaemonReload:
+		// Handled by the reload below
+	default:
+		return fmt.Errorf("unknown action %s", action)
+	}
+	if call != nil && call.Err != nil {
+		return fmt.Errorf("error running %s on unit %s over D-Bus: %v", action, unitName, call.Err)
+	}
+
+	if err := manager.Call(managerInterface+".Reload", 0).Err; err != nil {
+		return fmt.Errorf("error reloading the %s over D-Bus: %v", target.describe(), err)
+	}
+	return nil
+}
+
+// waitForJob waits until a queued job is gone from the bus, like a blocking systemctl call does,
+// giving up after actionSettleTimeout
+func waitForJob(conn *dbus.Conn, job dbus.ObjectPath) {
+	deadline := time.Now().Add(actionSettleTimeout)
+	for time.Now().Before(deadline) {
+		if _, err := conn.Object(systemdBusName, job).GetProperty(jobInterface + ".State"); err != nil {
+			return
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+}
+
+// formatVariant renders a property value the way `systemctl show` prints simple values
+func formatVariant(value dbus.Variant) string {
+	switch v := value.Value().(type) {
+	case string:
+		return v
+	case []string:
+		// Quote entries containing spaces like systemctl does, so splitWords can take them apart
+		quoted := make([]string, len(v))
+		for i, entry := range v {
+			if strings.ContainsAny(entry, " \t\"") {
+				entry = `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(entry) + `"`
+			}
+			quoted[i] = entry
+		}
+		return strings.Join(quoted, " ")
+	case bool:
+		if v {
+			return "yes"
+		}
+		return "no"
+	case dbus.ObjectPath:
+		return string(v)
+	case [][]interface{}:
+		// Arrays of (type, value) pairs such as Listen, printed one per line as "value (type)"
+		var entries []string
+		for _, entry := range v {
+			if len(entry) == 2 {
+				entries = append(entries, fmt.Sprintf("%v (%v)", entry[1], entry[0]))
+			}
+		}
+		return strings.Join(entries, "\n")
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,205 @@
+package systemd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shellcanary/discover/lib/models"
+)
+
+// Syslog priorities used by the journal
+const (
+	PriorityEmerg = iota
+	PriorityAlert
+	PriorityCrit
+	PriorityErr
+	PriorityWarning
+	PriorityNotice
+	PriorityInfo
+	PriorityDebug
+)
+
+// PriorityNames are the journalctl names of the priorities, indexed by priority
+var PriorityNames = []string{"emerg", "alert", "crit", "err", "warning", "notice", "info", "debug"}
+
+// JournalQuery selects the journal entries to read
+type JournalQuery struct {
+	// Boot is the boot offset: 0 is the current boot, -1 the previous one and so on
+	Boot int
+	// AllBoots ignores Boot and reads entries from every boot
+	AllBoots bool
+	// Priority keeps only entries at this priority name (e.g. "warning") or more severe; empty keeps all
+	Priority string
+	// Lines is how many of the most recent entries to read, 100 if not set
+	Lines int
+	// Fields are extra journal fields to keep on each entry
+	Fields []string
+}
+
+// GetServiceJournal reads structured journal entries of a service from the manager it belongs to
+func GetServiceJournal(service models.SystemdService, query JournalQuery) ([]models.JournalEntry, error) {
+	serviceName := serviceUnitName(service.Name)
+	return GetJournal(targetFor(service).journalArgs(serviceName), query)
+}
+
+// GetJournal reads structured journal entries for journalctl matches such as "_TRANSPORT=kernel"
+func GetJournal(matches []string, query JournalQuery) ([]models.JournalEntry, error) {
+	lines := query.Lines
+	if lines <= 0 {
+		lines = 100
+	}
+
+	args := append(append([]string{}, matches...), "--no-pager", "-o", "json", "-n", strconv.Itoa(lines))
+	if !query.AllBoots {
+		args = append(args, "-b", strconv.Itoa(query.Boot))
+	}
+	if query.Priority != "" {
+		args = append(args, "-p", query.Priority)
+	}
+
+	output, err := exec.Command("journalctl", args...).Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return nil, fmt.Errorf("error reading the journal: %v\n%s", err, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, fmt.Errorf("error reading the journal: %v", err)
+	}
+
+	return parseJournal(output, query.Fields)
+}
+
+// parseJournal parses journalctl JSON output, one object per line
+func parseJournal(output []byte, fields []string) ([]models.JournalEntry, error) {
+	var entries []models.JournalEntry
+
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	// Entries can carry large messages (e.g. stack traces)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		var record map[string]json.RawMessage
+		if err := json.Unmarshal(line, &record); err != nil {
+			return entries, fmt.Errorf("error parsing journal entry: %v", err)
+		}
+
+		entry := models.JournalEntry{
+			BootID:     journalField(record, "_BOOT_ID"),
+			Identifier: journalField(record, "SYSLOG_IDENTIFIER"),
+			Message:    journalField(record, "MESSAGE"),
+			Priority:   PriorityInfo,
+		}
+		if usec, err := strconv.ParseInt(journalField(record, "__REALTIME_TIMESTAMP"), 10, 64); err == nil {
+			entry.Timestamp = time.UnixMicro(usec)
+		}
+		if priority, err := strconv.Atoi(journalField(record, "PRIORITY")); err == nil {
+			entry.Priority = priority
+		}
+		entry.PID, _ = strconv.Atoi(journalField(record, "_PID"))
+
+		entry.Unit = journalField(record, "_SYSTEMD_UNIT")
+		if userUnit := journalField(record, "_SYSTEMD_USER_UNIT"); userUnit != "" {
+			entry.Unit = userUnit
+		}
+
+		for _, name := range fields {
+			if value := journalField(record, name); value != "" {
+				if entry.Fields == nil {
+					entry.Fields = make(map[string]string)
+				}
+				entry.Fields[name] = value
+			}
+		}
+
+		entries = append(entries, entry)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return entries, fmt.Errorf("error reading journal output: %v", err)
+	}
+	return entries, nil
+}
+
+// journalField decodes a field of a JSON journal entry. journalctl prints fields as strings,
+// as arrays of bytes when they are not valid UTF-8, and as arrays when a field repeats.
+func journalField(record map[string]json.RawMessage, name string) string {
+	raw, ok := record[name]
+	if !ok {
+		return ""
+	}
+
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return text
+	}
+
+	var data []byte
+	var numbers []int
+	if err := json.Unmarshal(raw, &numbers); err == nil {
+		for _, n := range numbers {
+			data = append(data, byte(n))
+		}
+		return string(data)
+	}
+
+	var values []json.RawMessage
+	if err := json.Unmarshal(raw, &values); err == nil && len(values) > 0 {
+		return journalField(map[string]json.RawMessage{name: values[0]}, name)
+	}
+	return ""
+}
+
+// ListBoots returns the boots recorded in the journal, oldest first
+func ListBoots() ([]models.JournalBoot, error) {
+	output, err := exec.Command("journalctl", "--list-boots", "--no-pager", "-o", "json").Output()
+	if err == nil {
+		var records []struct {
+			Index      int    `json:"index"`
+			BootID     string `json:"boot_id"`
+			FirstEntry int64  `json:"first_entry"`
+			LastEntry  int64  `json:"last_entry"`
+		}
+		if err := json.Unmarshal(output, &records); err == nil {
+			boots := make([]models.JournalBoot, 0, len(records))
+			for _, record := range records {
+				boots = append(boots, models.JournalBoot{
+					Offset: record.Index,
+					ID:     record.BootID,
+					First:  time.UnixMicro(record.FirstEntry),
+					Last:   time.UnixMicro(record.LastEntry),
+				})
+			}
+			return boots, nil
+		}
+	}
+
+	// Older journalctl versions only print a table: "-1 <boot id> <first entry>—<last entry>"
+	output, err = exec.Command("journalctl", "--list-boots", "--no-pager").Output()
+	if err != nil {
+		return nil, fmt.Errorf("error listing boots: %v", err)
+	}
+
+	var boots []models.JournalBoot
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		offset, err := strconv.Atoi(fields[0])
+		if err != nil {
+			// Header line
+			continue
+		}
+		boots = append(boots, models.JournalBoot{Offset: offset, ID: fields[1]})
+	}
+	return boots, nil
+}
@@ -0,0 +1,175 @@
+package systemd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/godbus/dbus/v5"
+
+	"github.com/shellcanary/discover/lib/models"
+)
+
+// Scopes of the systemd managers a service can belong to
+const (
+	ScopeSystem = "system"
+	ScopeUser   = "user"
+)
+
+// connectUserBus returns the connection to the user manager of the user running discover.
+// Like connectSystemBus it can be replaced with a private bus.
+var connectUserBus = userBus
+
+var (
+	userBusConn *dbus.Conn
+	userBusLock sync.Mutex
+)
+
+// userBus connects to the user bus socket in the runtime directory. Unlike dbus.SessionBus
+// it never autolaunches a bus daemon when the user has no session.
+func userBus() (*dbus.Conn, error) {
+	userBusLock.Lock()
+	defer userBusLock.Unlock()
+
+	if userBusConn != nil && userBusConn.Connected() {
+		return userBusConn, nil
+	}
+
+	runtimeDir := os.Getenv("XDG_RUNTIME_DIR")
+	if runtimeDir == "" {
+		runtimeDir = filepath.Join("/run/user", strconv.Itoa(os.Getuid()))
+	}
+	socket := filepath.Join(runtimeDir, "bus")
+	if _, err := os.Stat(socket); err != nil {
+		return nil, fmt.Errorf("no user bus at %s", socket)
+	}
+
+	conn, err := dbus.Connect("unix:path=" + socket)
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to the user bus: %v", err)
+	}
+	userBusConn = conn
+	return conn, nil
+}
+
+// managerTarget identifies the systemd manager that owns a unit
+type managerTarget struct {
+	scope string
+	// user is the owner of a user manager, empty for the system manager
+	user string
+	// current is set when the user manager belongs to the user running discover
+	current bool
+}
+
+// systemManager is the target for system units
+var systemManager = managerTarget{scope: ScopeSystem}
+
+// targetFor returns the manager a service belongs to
+func targetFor(service models.SystemdService) managerTarget {
+	if service.Scope != ScopeUser {
+		return systemManager
+	}
+
+	target := managerTarget{scope: ScopeUser, user: service.User}
+	if me, err := user.Current(); err == nil && (service.User == "" || service.User == me.Username) {
+		target.user = me.Username
+		target.current = true
+	}
+	return target
+}
+
+// bus connects to the manager over D-Bus. Other users' managers are only reachable through systemctl.
+func (t managerTarget) bus() (*dbus.Conn, error) {
+	switch {
+	case t.scope == ScopeSystem:
+		return connectSystemBus()
+	case t.current:
+		return connectUserBus()
+	default:
+		return nil, fmt.Errorf("the user manager of %s is not reachable over D-Bus", t.user)
+	}
+}
+
+// systemctlArgs returns the flags that point systemctl at the manager
+func (t managerTarget) systemctlArgs() []string {
+	switch {
+	case t.scope == ScopeSystem:
+		return nil
+	case t.current:
+		return []string{"--user"}
+	default:
+		return []string{"--user", "--machine=" + t.user + "@"}
+	}
+}
+
+// journalArgs returns the journalctl match for a unit of the manager
+func (t managerTarget) journalArgs(unitName string) []string {
+	if t.scope == ScopeSystem {
+		return []string{"-u", unitName}
+	}
+
+	args := []string{"--user-unit=" + unitName}
+	if owner, err := user.Lookup(t.user); err == nil {
+		// Only show this user's instance of the unit
+		args = append(args, "_UID="+owner.Uid)
+	}
+	return args
+}
+
+// describe names the manager for messages
+func (t managerTarget) describe() string {
+	if t.scope == ScopeSystem {
+		return "system manager"
+	}
+	return fmt.Sprintf("user manager of %s", t.user)
+}
+
+// service fills in the scope fields of a service listed from this manager
+func (t managerTarget) service(service models.SystemdService) models.SystemdService {
+	service.Scope, service.User = t.scopeFields()
+	return service
+}
+
+// scopeFields returns the Scope and User values recorded on units listed from this manager
+func (t managerTarget) scopeFields() (string, string) {
+	if t.scope == ScopeUser {
+		return t.scope, t.user
+	}
+	return t.scope, ""
+}
+
+// userManagers returns the user managers to list: the current user's, plus every
+// logged-in user's when running as root and all users were requested
+func userManagers(allUsers bool) []managerTarget {
+	me, err := user.Current()
+	if err != nil {
+		return nil
+	}
+
+	targets := []managerTarget{{scope: ScopeUser, user: me.Username, current: true}}
+	if !allUsers || os.Geteuid() != 0 {
+		return targets
+	}
+
+	// loginctl prints "UID USER ..." for every user with a session or lingering manager
+	output, err := exec.Command("loginctl", "list-users", "--no-legend").Output()
+	if err != nil {
+		fmt.Println("Warning: Failed to list logged-in users:", err)
+		return targets
+	}
+
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || fields[1] == me.Username {
+			continue
+		}
+		targets = append(targets, managerTarget{scope: ScopeUser, user: fields[1]})
+	}
+
+	return targets
+}
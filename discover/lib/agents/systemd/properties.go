@@ -0,0 +1,80 @@
+package systemd
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// redactedValue replaces secret values in a service's environment
+const redactedValue = "<redacted>"
+
+// secretNamePattern matches environment variable names that usually hold secrets
+var secretNamePattern = regexp.MustCompile(`(?i)(pass|secret|token|key|credential|auth|private|cert|dsn|cookie|session)`)
+
+// urlCredentialsPattern matches the password of credentials embedded in URLs, e.g. postgres://user:pw@host
+var urlCredentialsPattern = regexp.MustCompile(`(://[^:/@\s]+:)[^@\s]+@`)
+
+// parseCounter parses an accounting property. systemd reports unset or unavailable counters as
+// "[not set]" (systemctl) or the maximum value (D-Bus), which both come back as zero.
+func parseCounter(value string) uint64 {
+	counter, err := strconv.ParseUint(strings.TrimSpace(value), 10, 64)
+	if err != nil || counter == math.MaxUint64 {
+		return 0
+	}
+	return counter
+}
+
+// splitWords splits a list property printed by `systemctl show`, where entries containing spaces
+// are double-quoted
+func splitWords(value string) []string {
+	var words []string
+	var word strings.Builder
+	inWord, quoted, escaped := false, false, false
+
+	for _, r := range value {
+		switch {
+		case escaped:
+			word.WriteRune(r)
+			escaped = false
+		case r == '\\' && quoted:
+			escaped = true
+		case r == '"':
+			quoted = !quoted
+			inWord = true
+		case (r == ' ' || r == '\t' || r == '\n') && !quoted:
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+
+	return words
+}
+
+// redactEnvironment hides the values of KEY=value assignments whose names look like secrets,
+// as well as passwords embedded in URLs
+func redactEnvironment(environment []string) []string {
+	redacted := make([]string, 0, len(environment))
+	for _, assignment := range environment {
+		name, value, found := strings.Cut(assignment, "=")
+		switch {
+		case !found:
+			redacted = append(redacted, assignment)
+		case secretNamePattern.MatchString(name) && value != "":
+			redacted = append(redacted, name+"="+redactedValue)
+		default:
+			redacted = append(redacted, name+"="+urlCredentialsPattern.ReplaceAllString(value, "${1}"+redactedValue+"@"))
+		}
+	}
+	return redacted
+}
@@ -2,109 +2,125 @@ package systemd
 
 import (
 	"fmt"
+	"os"
 	"os/exec"
+	"strconv"
 	"strings"
-	"regexp"
 
+	"github.com/shellcanary/discover/lib/config"
 	"github.com/shellcanary/discover/lib/models"
 )
 
-// GetSystemdServices returns a list of systemd services
+// unitStatus is a unit as listed by either the D-Bus or the CLI backend
+type unitStatus struct {
+	Name        string
+	Description string
+	LoadState   string
+	ActiveState string
+	SubState    string
+}
+
+// serviceDetailProperties are the properties shown in the service details screen
+var serviceDetailProperties = []string{
+	"Id", "Description", "LoadState", "ActiveState", "SubState", "UnitFileState",
+	"ExecMainPID", "ExecMainStatus", "Type", "Restart",
+	"MemoryCurrent", "MemoryPeak", "CPUUsageNSec", "TasksCurrent", "IOReadBytes", "IOWriteBytes",
+	"NRestarts", "ActiveEnterTimestamp", "ExecMainStartTimestamp", "FragmentPath", "DropInPaths",
+	"User", "Environment",
+}
+
+// GetSystemdServices returns a list of systemd services from the system manager
+// and the user managers discover can see
 func GetSystemdServices() []models.SystemdService {
 	var services []models.SystemdService
 
-	// Check if systemctl is available
-	cmd := exec.Command("systemctl", "--version")
-	if err := cmd.Run(); err != nil {
-		fmt.Println("Warning: systemctl command failed, systemd might not be available")
-		return services
-	}
+	for _, unit := range listManagedUnits("service") {
+		// Extract service name without .service suffix
+		serviceName := strings.TrimSuffix(unit.Name, ".service")
 
-	// Get list of all services
-	cmd = exec.Command("systemctl", "list-units", "--type=service", "--all", "--no-pager", "--plain")
-	output, err := cmd.Output()
-	if err != nil {
-		fmt.Println("Warning: Failed to list systemd services:", err)
-		return services
+		services = append(services, unit.target.service(models.SystemdService{
+			Name:        serviceName,
+			Status:      unit.status(),
+			SubStatus:   unit.SubState,
+			Description: unit.Description,
+		}))
 	}
 
-	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
-	
-	// Skip the header line and process each service
-	for i, line := range lines {
-		if i == 0 { // Skip header
+	// Keep the full details of the services marked important, so they end up in the state file
+	settings := config.Current().Systemd
+	for i := range services {
+		if !settings.Important(services[i].Name) {
 			continue
 		}
-		
-		// Remove extra spaces and split by whitespace
-		line = regexp.MustCompile(`\s+`).ReplaceAllString(strings.TrimSpace(line), " ")
-		parts := strings.SplitN(line, " ", 5)
-		
-		// Check if we have enough parts
-		if len(parts) < 4 {
+		detail, err := GetServiceStatus(services[i])
+		if err != nil {
+			fmt.Println("Warning:", err)
 			continue
 		}
-		
-		// Extract service name without .service suffix
-		serviceName := parts[0]
-		if strings.HasSuffix(serviceName, ".service") {
-			serviceName = serviceName[:len(serviceName)-8] // Remove ".service"
-		}
-		
-		// Extract status and description
-		loadStatus := parts[1]
-		activeStatus := parts[2]
-		subStatus := parts[3]
-		description := ""
-		if len(parts) >= 5 {
-			description = parts[4]
+		services[i].Detail = &detail
+	}
+
+	return services
+}
+
+// managedUnit is a listed unit along with the manager it came from
+type managedUnit struct {
+	unitStatus
+	target managerTarget
+}
+
+// listManagedUnits lists the units of a type from the system manager and the visible user managers
+func listManagedUnits(unitType string) []managedUnit {
+	var managed []managedUnit
+
+	units, err := listUnits(systemManager, unitType)
+	if err != nil {
+		fmt.Println("Warning:", err)
+	}
+	for _, unit := range units {
+		managed = append(managed, managedUnit{unitStatus: unit, target: systemManager})
+	}
+
+	// User managers are often not running (e.g. no login session), so skip them quietly
+	for _, target := range userManagers(config.Current().Systemd.AllUsers) {
+		units, err := listUnits(target, unitType)
+		if err != nil {
+			continue
 		}
-		
-		// Combine load and active status
-		status := activeStatus
-		if loadStatus != "loaded" {
-			status = fmt.Sprintf("%s (%s)", activeStatus, loadStatus)
+		for _, unit := range units {
+			managed = append(managed, managedUnit{unitStatus: unit, target: target})
 		}
-		
-		services = append(services, models.SystemdService{
-			Name:        serviceName,
-			Status:      status,
-			SubStatus:   subStatus,
-			Description: description,
-		})
 	}
 
-	return services
+	return managed
 }
 
-// GetSystemdServiceStatus retrieves the detailed status of a specific systemd service
+// status combines the load and active state the way the service list shows it
+func (u unitStatus) status() string {
+	if u.LoadState != "loaded" {
+		return fmt.Sprintf("%s (%s)", u.ActiveState, u.LoadState)
+	}
+	return u.ActiveState
+}
+
+// GetSystemdServiceStatus retrieves the detailed status of a specific system service
 func GetSystemdServiceStatus(serviceName string) (models.SystemdServiceDetail, error) {
+	return GetServiceStatus(models.SystemdService{Name: serviceName})
+}
+
+// GetServiceStatus retrieves the detailed status of a service from the manager it belongs to
+func GetServiceStatus(service models.SystemdService) (models.SystemdServiceDetail, error) {
 	var detail models.SystemdServiceDetail
-	
+
 	// Construct the service name with .service suffix if not present
-	if !strings.HasSuffix(serviceName, ".service") {
-		serviceName = serviceName + ".service"
-	}
-	
+	serviceName := serviceUnitName(service.Name)
+
 	// Get service properties
-	cmd := exec.Command("systemctl", "show", 
-		"--property=Id,Description,LoadState,ActiveState,SubState,UnitFileState,ExecMainPID,ExecMainStatus,Type,Restart",
-		serviceName)
-	output, err := cmd.CombinedOutput()
+	properties, err := unitProperties(targetFor(service), serviceName, serviceDetailProperties)
 	if err != nil {
 		return detail, fmt.Errorf("error retrieving details for service %s: %v", serviceName, err)
 	}
-	
-	// Parse the output
-	properties := make(map[string]string)
-	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
-	for _, line := range lines {
-		parts := strings.SplitN(line, "=", 2)
-		if len(parts) == 2 {
-			properties[parts[0]] = parts[1]
-		}
-	}
-	
+
 	// Create the service detail
 	detail = models.SystemdServiceDetail{
 		Id:             properties["Id"],
@@ -117,20 +133,38 @@ func GetSystemdServiceStatus(serviceName string) (models.SystemdServiceDetail, e
 		ExecMainStatus: properties["ExecMainStatus"],
 		Type:           properties["Type"],
 		Restart:        properties["Restart"],
+
+		MemoryCurrent:          parseCounter(properties["MemoryCurrent"]),
+		MemoryPeak:             parseCounter(properties["MemoryPeak"]),
+		CPUUsageNSec:           parseCounter(properties["CPUUsageNSec"]),
+		TasksCurrent:           parseCounter(properties["TasksCurrent"]),
+		IOReadBytes:            parseCounter(properties["IOReadBytes"]),
+		IOWriteBytes:           parseCounter(properties["IOWriteBytes"]),
+		ActiveEnterTimestamp:   parseTimestamp(properties["ActiveEnterTimestamp"]),
+		ExecMainStartTimestamp: parseTimestamp(properties["ExecMainStartTimestamp"]),
+		FragmentPath:           properties["FragmentPath"],
+		DropInPaths:            splitWords(properties["DropInPaths"]),
+		User:                   properties["User"],
+		Environment:            redactEnvironment(splitWords(properties["Environment"])),
 	}
-	
+	detail.NRestarts, _ = strconv.Atoi(properties["NRestarts"])
+
 	return detail, nil
 }
 
-// GetSystemdServiceLogs retrieves logs for a specific systemd service
+// GetSystemdServiceLogs retrieves logs for a specific system service
 func GetSystemdServiceLogs(serviceName string) string {
+	return GetServiceLogs(models.SystemdService{Name: serviceName})
+}
+
+// GetServiceLogs retrieves logs for a service from the manager it belongs to
+func GetServiceLogs(service models.SystemdService) string {
 	// Ensure service name has .service suffix
-	if !strings.HasSuffix(serviceName, ".service") {
-		serviceName = serviceName + ".service"
-	}
-	
+	serviceName := serviceUnitName(service.Name)
+
 	// Use journalctl to get logs for the service
-	cmd := exec.Command("journalctl", "-u", serviceName, "--no-pager", "-n", "100")
+	args := append(targetFor(service).journalArgs(serviceName), "--no-pager", "-n", "100")
+	cmd := exec.Command("journalctl", args...)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return fmt.Sprintf("Error retrieving logs for service %s: %v", serviceName, err)
@@ -138,19 +172,124 @@ func GetSystemdServiceLogs(serviceName string) string {
 	return string(output)
 }
 
-// RestartSystemdService attempts to restart a systemd service
+// RestartSystemdService attempts to restart a system service
 func RestartSystemdService(serviceName string) error {
-	// Ensure service name has .service suffix
-	if !strings.HasSuffix(serviceName, ".service") {
-		serviceName = serviceName + ".service"
+	return RestartService(models.SystemdService{Name: serviceName})
+}
+
+// RestartService attempts to restart a service through the manager it belongs to
+func RestartService(service models.SystemdService) error {
+	_, err := ControlService(service, ActionRestart)
+	return err
+}
+
+// serviceUnitName adds the .service suffix if not present. Names of other unit types, such as
+// scopes found through cgroups, are kept as they are.
+func serviceUnitName(serviceName string) string {
+	for _, suffix := range []string{".service", ".scope", ".socket", ".mount", ".swap"} {
+		if strings.HasSuffix(serviceName, suffix) {
+			return serviceName
+		}
+	}
+	return serviceName + ".service"
+}
+
+// listUnits lists units of a type (all units if empty) from a manager, preferring D-Bus over
+// `systemctl list-units`
+func listUnits(target managerTarget, unitType string) ([]unitStatus, error) {
+	suffix := ""
+	if unitType != "" {
+		suffix = "." + unitType
 	}
-	
-	cmd := exec.Command("systemctl", "restart", serviceName)
+	if units, err := listUnitsDBus(target, suffix); err == nil {
+		return units, nil
+	}
+	return listUnitsCLI(target, unitType)
+}
+
+// unitProperties reads the named properties of a unit, preferring D-Bus over `systemctl show`
+func unitProperties(target managerTarget, unitName string, names []string) (map[string]string, error) {
+	if properties, err := unitPropertiesDBus(target, unitName); err == nil {
+		return properties, nil
+	}
+	return unitPropertiesCLI(target, unitName, names)
+}
+
+// listUnitsCLI lists units of a type by parsing `systemctl list-units` output
+func listUnitsCLI(target managerTarget, unitType string) ([]unitStatus, error) {
+	// Check if systemctl is available
+	cmd := exec.Command("systemctl", "--version")
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("systemctl command failed, systemd might not be available")
+	}
+
+	// Get list of all units of this type, without the header and footer
+	args := []string{"list-units", "--all", "--no-pager", "--plain", "--no-legend"}
+	if unitType != "" {
+		args = append(args, "--type="+unitType)
+	}
+	cmd = systemctlCommand(target, args...)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list systemd units from the %s: %v", target.describe(), err)
+	}
+
+	var units []unitStatus
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		fields := strings.Fields(line)
+
+		// Failed or not-found units are prefixed with a status marker on some versions
+		if len(fields) > 0 && (fields[0] == "●" || fields[0] == "*") {
+			fields = fields[1:]
+		}
+
+		// Check if we have enough parts
+		if len(fields) < 4 {
+			continue
+		}
+
+		units = append(units, unitStatus{
+			Name:        fields[0],
+			LoadState:   fields[1],
+			ActiveState: fields[2],
+			SubState:    fields[3],
+			Description: strings.Join(fields[4:], " "),
+		})
+	}
+
+	return units, nil
+}
+
+// unitPropertiesCLI reads the named properties of a unit with `systemctl show`
+func unitPropertiesCLI(target managerTarget, unitName string, names []string) (map[string]string, error) {
+	cmd := systemctlCommand(target, "show", "--property="+strings.Join(names, ","), unitName)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
-		return fmt.Errorf("failed to restart service %s: %v\nOutput: %s", 
-			serviceName, err, string(output))
+		return nil, fmt.Errorf("%v: %s", err, strings.TrimSpace(string(output)))
+	}
+
+	// Parse the output, keeping every value of properties printed once per entry (e.g. Listen)
+	properties := make(map[string]string)
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	for _, line := range lines {
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		if existing, ok := properties[parts[0]]; ok && existing != "" {
+			properties[parts[0]] = existing + "\n" + parts[1]
+		} else {
+			properties[parts[0]] = parts[1]
+		}
 	}
-	
-	return nil
-}
\ No newline at end of file
+
+	return properties, nil
+}
+
+// systemctlCommand builds a systemctl command for a manager, with the C locale so its
+// output parses the same everywhere
+func systemctlCommand(target managerTarget, args ...string) *exec.Cmd {
+	cmd := exec.Command("systemctl", append(target.systemctlArgs(), args...)...)
+	cmd.Env = append(os.Environ(), "LC_ALL=C")
+	return cmd
+}
@@ -0,0 +1,158 @@
+package systemd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/shellcanary/discover/lib/models"
+	"github.com/shellcanary/discover/lib/sessions"
+)
+
+// overrideFileName is the drop-in created for overrides, the same one `systemctl edit` uses
+const overrideFileName = "override.conf"
+
+// GetUnitFile returns the effective configuration of a service: its unit file followed by all
+// drop-ins, as printed by `systemctl cat`
+func GetUnitFile(service models.SystemdService) (string, error) {
+	serviceName := serviceUnitName(service.Name)
+
+	output, err := systemctlCommand(targetFor(service), "cat", "--no-pager", serviceName).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("error reading unit file of %s: %v\nOutput: %s", serviceName, err, string(output))
+	}
+	return string(output), nil
+}
+
+// OverridePath returns the path of the override drop-in of a service
+func OverridePath(service models.SystemdService) (string, error) {
+	serviceName := serviceUnitName(service.Name)
+	target := targetFor(service)
+
+	switch {
+	case target.scope == ScopeSystem:
+		return filepath.Join("/etc/systemd/system", serviceName+".d", overrideFileName), nil
+	case target.current:
+		configDir, err := os.UserConfigDir()
+		if err != nil {
+			return "", fmt.Errorf("error finding the user config directory: %v", err)
+		}
+		return filepath.Join(configDir, "systemd", "user", serviceName+".d", overrideFileName), nil
+	default:
+		// systemd-analyze can only verify the units of the current user
+		return "", fmt.Errorf("overrides can only be edited for system services and your own user services")
+	}
+}
+
+// EditOverride opens the override drop-in of a service in $EDITOR, then validates the unit with
+// `systemd-analyze verify`. Invalid overrides are reverted. It reports whether the override was
+// changed; the manager still has to be reloaded for the change to take effect.
+func EditOverride(service models.SystemdService) (bool, error) {
+	serviceName := serviceUnitName(service.Name)
+	path, err := OverridePath(service)
+	if err != nil {
+		return false, err
+	}
+
+	// Start from the current override, or a skeleton for a new one
+	previous, err := ioutil.ReadFile(path)
+	existed := err == nil
+	if err != nil && !os.IsNotExist(err) {
+		return false, fmt.Errorf("error reading %s: %v", path, err)
+	}
+	content := previous
+	if !existed {
+		content = []byte(fmt.Sprintf("# Override for %s\n# Settings here are merged into the unit, see `systemctl cat %s`\n\n[Service]\n",
+			serviceName, serviceName))
+	}
+
+	// Edit a copy so an aborted edit leaves the drop-in untouched
+	edited, err := editInEditor(content)
+	if err != nil {
+		return false, err
+	}
+	if string(edited) == string(content) {
+		return false, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return false, fmt.Errorf("error creating %s: %v", filepath.Dir(path), err)
+	}
+	if err := ioutil.WriteFile(path, edited, 0644); err != nil {
+		return false, fmt.Errorf("error writing %s: %v (system overrides need root)", path, err)
+	}
+
+	if err := verifyUnit(targetFor(service), serviceName); err != nil {
+		// Put the previous override back so the broken one is never loaded
+		if existed {
+			ioutil.WriteFile(path, previous, 0644)
+		} else {
+			os.Remove(path)
+		}
+		return false, fmt.Errorf("override for %s rejected, changes reverted: %v", serviceName, err)
+	}
+
+	return true, nil
+}
+
+// editInEditor lets the user edit content in $VISUAL or $EDITOR (vi if neither is set)
+func editInEditor(content []byte) ([]byte, error) {
+	file, err := ioutil.TempFile("", "discover-override-*.conf")
+	if err != nil {
+		return nil, fmt.Errorf("error creating temporary file: %v", err)
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.Write(content); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("error writing temporary file: %v", err)
+	}
+	file.Close()
+
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	// The editor setting may carry arguments, e.g. "code --wait"
+	args := append(strings.Fields(editor), file.Name())
+	if err := sessions.RunInteractive(exec.Command(args[0], args[1:]...)); err != nil {
+		return nil, fmt.Errorf("editor %s failed: %v", editor, err)
+	}
+
+	edited, err := ioutil.ReadFile(file.Name())
+	if err != nil {
+		return nil, fmt.Errorf("error reading edited file: %v", err)
+	}
+	return edited, nil
+}
+
+// verifyUnit checks a unit and its drop-ins with `systemd-analyze verify`
+func verifyUnit(target managerTarget, unitName string) error {
+	if _, err := exec.LookPath("systemd-analyze"); err != nil {
+		fmt.Println("Warning: systemd-analyze not found, the override was not validated")
+		return nil
+	}
+
+	args := []string{"verify", unitName}
+	if target.scope == ScopeUser {
+		args = append([]string{"--user"}, args...)
+	}
+	cmd := exec.Command("systemd-analyze", args...)
+	cmd.Env = append(os.Environ(), "LC_ALL=C")
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%v\n%s", err, strings.TrimSpace(string(output)))
+	}
+	if warnings := strings.TrimSpace(string(output)); warnings != "" {
+		fmt.Println(warnings)
+	}
+	return nil
+}
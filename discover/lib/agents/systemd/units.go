@@ -0,0 +1,171 @@
+package systemd
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shellcanary/discover/lib/models"
+)
+
+// Properties read for each of the non-service unit types
+var (
+	timerProperties  = []string{"Triggers", "NextElapseUSecRealtime", "LastTriggerUSec"}
+	socketProperties = []string{"Triggers", "Listen"}
+	mountProperties  = []string{"Where", "What", "Type"}
+)
+
+// GetSystemdTimers returns the timers of the system manager and the visible user managers,
+// with when they fire next, when they last fired and how the activated service's last run ended
+func GetSystemdTimers() []models.SystemdTimer {
+	var timers []models.SystemdTimer
+
+	for _, unit := range listManagedUnits("timer") {
+		timer := models.SystemdTimer{
+			Name:        unit.Name,
+			Status:      unit.status(),
+			Description: unit.Description,
+		}
+		timer.Scope, timer.User = unit.target.scopeFields()
+
+		properties, err := unitProperties(unit.target, unit.Name, timerProperties)
+		if err != nil {
+			fmt.Printf("Warning: Failed to read properties of %s: %v\n", unit.Name, err)
+			timers = append(timers, timer)
+			continue
+		}
+
+		timer.Unit = firstField(properties["Triggers"])
+		timer.NextElapse = parseTimestamp(properties["NextElapseUSecRealtime"])
+		timer.LastTrigger = parseTimestamp(properties["LastTriggerUSec"])
+
+		// The result is only meaningful once the timer has fired
+		if timer.Unit != "" && !timer.LastTrigger.IsZero() {
+			if service, err := unitProperties(unit.target, timer.Unit, []string{"Result"}); err == nil {
+				timer.LastResult = service["Result"]
+			}
+		}
+
+		timers = append(timers, timer)
+	}
+
+	return timers
+}
+
+// GetSystemdSockets returns the socket units with their listen addresses
+func GetSystemdSockets() []models.SystemdSocket {
+	var sockets []models.SystemdSocket
+
+	for _, unit := range listManagedUnits("socket") {
+		socket := models.SystemdSocket{
+			Name:        unit.Name,
+			Status:      unit.status(),
+			Description: unit.Description,
+		}
+		socket.Scope, socket.User = unit.target.scopeFields()
+
+		properties, err := unitProperties(unit.target, unit.Name, socketProperties)
+		if err != nil {
+			fmt.Printf("Warning: Failed to read properties of %s: %v\n", unit.Name, err)
+			sockets = append(sockets, socket)
+			continue
+		}
+
+		socket.Unit = firstField(properties["Triggers"])
+		if listen := strings.TrimSpace(properties["Listen"]); listen != "" {
+			socket.Listen = strings.Split(listen, "\n")
+		}
+
+		sockets = append(sockets, socket)
+	}
+
+	return sockets
+}
+
+// GetSystemdMounts returns the mount units with their source, mount point and filesystem type
+func GetSystemdMounts() []models.SystemdMount {
+	var mounts []models.SystemdMount
+
+	for _, unit := range listManagedUnits("mount") {
+		mount := models.SystemdMount{
+			Name:        unit.Name,
+			Status:      unit.status(),
+			Description: unit.Description,
+		}
+		mount.Scope, mount.User = unit.target.scopeFields()
+
+		properties, err := unitProperties(unit.target, unit.Name, mountProperties)
+		if err != nil {
+			fmt.Printf("Warning: Failed to read properties of %s: %v\n", unit.Name, err)
+			mounts = append(mounts, mount)
+			continue
+		}
+
+		mount.Where = properties["Where"]
+		mount.What = properties["What"]
+		mount.Type = properties["Type"]
+
+		mounts = append(mounts, mount)
+	}
+
+	return mounts
+}
+
+// GetSystemdTargets returns the target units
+func GetSystemdTargets() []models.SystemdTarget {
+	var targets []models.SystemdTarget
+
+	for _, unit := range listManagedUnits("target") {
+		target := models.SystemdTarget{
+			Name:        unit.Name,
+			Status:      unit.status(),
+			Description: unit.Description,
+		}
+		target.Scope, target.User = unit.target.scopeFields()
+		targets = append(targets, target)
+	}
+
+	return targets
+}
+
+// firstField returns the first entry of a space-separated list property
+func firstField(value string) string {
+	fields := strings.Fields(value)
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[0]
+}
+
+// parseTimestamp parses a timestamp property as read over D-Bus (microseconds since the epoch)
+// or printed by `systemctl show`. Unset timestamps come back as the zero time.
+func parseTimestamp(value string) time.Time {
+	value = strings.TrimSpace(value)
+	if value == "" || value == "0" || value == "n/a" {
+		return time.Time{}
+	}
+
+	// `systemctl show --timestamp=unix` prints "@seconds"
+	if strings.HasPrefix(value, "@") {
+		if seconds, err := strconv.ParseInt(value[1:], 10, 64); err == nil {
+			return time.Unix(seconds, 0)
+		}
+		return time.Time{}
+	}
+
+	if usec, err := strconv.ParseUint(value, 10, 64); err == nil {
+		// systemd uses the maximum value for "infinity", i.e. never
+		if usec == math.MaxUint64 {
+			return time.Time{}
+		}
+		return time.UnixMicro(int64(usec))
+	}
+
+	// Default systemctl format, e.g. "Mon 2026-10-19 02:00:00 UTC"
+	if t, err := time.ParseInLocation("Mon 2006-01-02 15:04:05 MST", value, time.Local); err == nil {
+		return t
+	}
+	return time.Time{}
+}
@@ -5,9 +5,20 @@ package discover
 import (
 	"fmt"
 
+	"github.com/shellcanary/discover/lib/agents/cgroup"
+	"github.com/shellcanary/discover/lib/agents/containerd"
+	"github.com/shellcanary/discover/lib/agents/cron"
 	"github.com/shellcanary/discover/lib/agents/docker"
+	"github.com/shellcanary/discover/lib/agents/host"
+	"github.com/shellcanary/discover/lib/agents/kernel"
 	"github.com/shellcanary/discover/lib/agents/kubernetes"
+	"github.com/shellcanary/discover/lib/agents/libvirt"
+	"github.com/shellcanary/discover/lib/agents/pm2"
+	"github.com/shellcanary/discover/lib/agents/ports"
+	"github.com/shellcanary/discover/lib/agents/processes"
+	"github.com/shellcanary/discover/lib/agents/supervisor"
 	"github.com/shellcanary/discover/lib/agents/systemd"
+	"github.com/shellcanary/discover/lib/config"
 	"github.com/shellcanary/discover/lib/models"
 	"github.com/shellcanary/discover/lib/state"
 )
@@ -24,27 +35,48 @@ func New() *Discover {
 	}
 }
 
-// CaptureSystemState captures the current state of all resources
+// SetConfig replaces the settings used by the agents, which otherwise come from ~/.discover/config.json
+func (d *Discover) SetConfig(cfg config.Config) {
+	config.Set(cfg)
+}
+
+// CaptureSystemState captures the current state of all resources and stores it as a new snapshot
 func (d *Discover) CaptureSystemState() error {
+	snapshot, err := state.LoadState()
+	if err != nil {
+		return fmt.Errorf("error loading system state: %v", err)
+	}
+	
 	// Gather data from all agents
-	dockerProjects := docker.GetDockerComposeProjects()
-	k8sConfigs := kubernetes.GetKubernetesConfigs()
-	systemdServices := systemd.GetSystemdServices()
+	snapshot.DockerProjects = docker.GetDockerComposeProjects()
+	snapshot.KubernetesConfigs = kubernetes.GetKubernetesConfigs()
+	snapshot.SystemdServices = systemd.GetSystemdServices()
+	
+	// Record what machine this state describes
+	facts := host.GetHostFacts()
+	snapshot.Host = &facts
 	
-	// Update the local state
-	d.State.DockerProjects = dockerProjects
-	d.State.KubernetesConfigs = k8sConfigs
-	d.State.SystemdServices = systemdServices
+	// Record this boot's performance; systemd-analyze only reports it once booting has finished
+	if analysis, err := systemd.GetBootAnalysis(); err == nil {
+		state.AddBootAnalysis(&snapshot, analysis)
+	}
 	
-	// Update system state file
-	err := state.UpdateSystemState(dockerProjects, k8sConfigs, systemdServices)
+	snapshotID, err := state.SaveSnapshot(snapshot)
 	if err != nil {
 		return fmt.Errorf("error updating system state: %v", err)
 	}
 	
+	snapshot.SnapshotID = snapshotID
+	d.State = snapshot
 	return nil
 }
 
+// GetHostFacts returns the hostname, OS release, kernel, architecture, uptime, load, memory and
+// filesystem usage of this machine
+func (d *Discover) GetHostFacts() models.HostFacts {
+	return host.GetHostFacts()
+}
+
 // GetDockerProjects returns Docker compose projects
 func (d *Discover) GetDockerProjects() []models.DockerProject {
 	return docker.GetDockerComposeProjects()
@@ -70,6 +102,31 @@ func (d *Discover) GetKubernetesLogs(contextName, deploymentName string) string
 	return kubernetes.GetKubernetesLogs(contextName, deploymentName)
 }
 
+// GetKubernetesDeploymentRoutes returns the services and ingresses that route traffic to a deployment
+func (d *Discover) GetKubernetesDeploymentRoutes(contextName, deploymentName string) ([]models.KubernetesService, []models.KubernetesIngress, error) {
+	return kubernetes.GetDeploymentRoutes(contextName, deploymentName)
+}
+
+// GetHelmReleases returns the Helm releases in a Kubernetes context along with the deployments they own
+func (d *Discover) GetHelmReleases(contextName string) ([]models.HelmRelease, error) {
+	return kubernetes.GetHelmReleases(contextName)
+}
+
+// GetKubernetesCustomResources returns the configured custom resource kinds across all namespaces in a context
+func (d *Discover) GetKubernetesCustomResources(contextName string) ([]models.KubernetesCustomResource, error) {
+	return kubernetes.GetCustomResources(contextName)
+}
+
+// GetKubernetesNodeUsage returns node CPU and memory usage from the metrics API
+func (d *Discover) GetKubernetesNodeUsage(contextName string) ([]models.KubernetesNodeUsage, error) {
+	return kubernetes.GetNodeUsage(contextName)
+}
+
+// GetKubernetesDeploymentUsage returns per-container usage of a deployment compared with its requests and limits
+func (d *Discover) GetKubernetesDeploymentUsage(contextName, deploymentName string) ([]models.KubernetesContainerUsage, error) {
+	return kubernetes.GetDeploymentUsage(contextName, deploymentName)
+}
+
 // GetSystemdServices returns systemd services
 func (d *Discover) GetSystemdServices() []models.SystemdService {
 	return systemd.GetSystemdServices()
@@ -90,7 +147,220 @@ func (d *Discover) RestartSystemdService(serviceName string) error {
 	return systemd.RestartSystemdService(serviceName)
 }
 
-// LoadStateFromFile loads system state from the state file
+// GetServiceStatus retrieves detailed status of a system or user service from the manager it belongs to
+func (d *Discover) GetServiceStatus(service models.SystemdService) (models.SystemdServiceDetail, error) {
+	return systemd.GetServiceStatus(service)
+}
+
+// GetServiceLogs retrieves logs for a system or user service
+func (d *Discover) GetServiceLogs(service models.SystemdService) string {
+	return systemd.GetServiceLogs(service)
+}
+
+// RestartService attempts to restart a system or user service through the manager it belongs to
+func (d *Discover) RestartService(service models.SystemdService) error {
+	return systemd.RestartService(service)
+}
+
+// ControlService starts, stops, reloads, enables, disables, masks or unmasks a service, or reloads
+// its manager, and reports the state the service reached (see the systemd.Action constants)
+func (d *Discover) ControlService(service models.SystemdService, action string) (models.SystemdActionResult, error) {
+	return systemd.ControlService(service, action)
+}
+
+// ControlServiceEscalated retries an action refused with an authorization error using polkit or sudo
+func (d *Discover) ControlServiceEscalated(service models.SystemdService, action, method string) (models.SystemdActionResult, error) {
+	return systemd.ControlServiceEscalated(service, action, method)
+}
+
+// GetFailedUnits returns the units in the failed state with their result, exit status,
+// restart count, failure time and last journal lines
+func (d *Discover) GetFailedUnits() []models.SystemdFailedUnit {
+	return systemd.GetFailedUnits()
+}
+
+// GetDependencyGraph builds the Requires/Wants/After/PartOf graph of the manager a service
+// belongs to; use systemd.UnitDependencies, systemd.GraphDOT and systemd.GraphMermaid to read it
+func (d *Discover) GetDependencyGraph(service models.SystemdService) (models.SystemdDependencyGraph, error) {
+	return systemd.GetDependencyGraph(service)
+}
+
+// GetUnitFile returns a service's unit file followed by its drop-ins, like `systemctl cat`
+func (d *Discover) GetUnitFile(service models.SystemdService) (string, error) {
+	return systemd.GetUnitFile(service)
+}
+
+// EditOverride edits a service's override drop-in in $EDITOR and validates it with
+// `systemd-analyze verify`; reload the manager with ControlService afterwards
+func (d *Discover) EditOverride(service models.SystemdService) (bool, error) {
+	return systemd.EditOverride(service)
+}
+
+// GetServiceJournal reads structured journal entries of a service for a boot and priority
+func (d *Discover) GetServiceJournal(service models.SystemdService, query systemd.JournalQuery) ([]models.JournalEntry, error) {
+	return systemd.GetServiceJournal(service, query)
+}
+
+// ListBoots returns the boots recorded in the journal
+func (d *Discover) ListBoots() ([]models.JournalBoot, error) {
+	return systemd.ListBoots()
+}
+
+// CaptureBootAnalysis analyzes the current boot (total time, blame and critical chain) and
+// stores it in the state file
+func (d *Discover) CaptureBootAnalysis() (models.BootAnalysis, error) {
+	analysis, err := systemd.GetBootAnalysis()
+	if err != nil {
+		return analysis, err
+	}
+	return analysis, state.SaveBootAnalysis(analysis)
+}
+
+// CompareWithPreviousBoot compares a boot analysis with the most recent other boot in the state
+// file, returning the units ordered by how much slower they got. It reports false when no
+// other boot has been captured.
+func (d *Discover) CompareWithPreviousBoot(analysis models.BootAnalysis) ([]models.BootUnitChange, bool, error) {
+	previous, ok, err := state.PreviousBootAnalysis(analysis.BootID)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	return systemd.CompareBoots(previous, analysis), true, nil
+}
+
+// GetSystemdTimers returns systemd timers with their next and last run
+func (d *Discover) GetSystemdTimers() []models.SystemdTimer {
+	return systemd.GetSystemdTimers()
+}
+
+// GetSystemdSockets returns systemd socket units with their listen addresses
+func (d *Discover) GetSystemdSockets() []models.SystemdSocket {
+	return systemd.GetSystemdSockets()
+}
+
+// GetSystemdMounts returns systemd mount units
+func (d *Discover) GetSystemdMounts() []models.SystemdMount {
+	return systemd.GetSystemdMounts()
+}
+
+// GetSystemdTargets returns systemd target units
+func (d *Discover) GetSystemdTargets() []models.SystemdTarget {
+	return systemd.GetSystemdTargets()
+}
+
+// GetContainerdNamespaces returns the containerd namespaces
+func (d *Discover) GetContainerdNamespaces() ([]string, error) {
+	return containerd.GetNamespaces()
+}
+
+// GetRuntimeContainers returns the containers run by containerd, with their pods for CRI containers
+func (d *Discover) GetRuntimeContainers() ([]models.RuntimeContainer, error) {
+	return containerd.GetRuntimeContainers()
+}
+
+// GetRuntimeImages returns the images stored by containerd
+func (d *Discover) GetRuntimeImages() ([]models.RuntimeImage, error) {
+	return containerd.GetRuntimeImages()
+}
+
+// GetPodSandboxes returns the CRI pod sandboxes on this node
+func (d *Discover) GetPodSandboxes() ([]models.PodSandbox, error) {
+	return containerd.GetPodSandboxes()
+}
+
+// GetRuntimeContainerLogs retrieves the last lines of a containerd container's log
+func (d *Discover) GetRuntimeContainerLogs(container models.RuntimeContainer) string {
+	return containerd.GetRuntimeContainerLogs(&container)
+}
+
+// GetSupervisorPrograms returns the supervisord programs with their state, PID, uptime and restarts
+func (d *Discover) GetSupervisorPrograms() ([]models.ManagedProgram, error) {
+	return supervisor.GetSupervisorPrograms()
+}
+
+// GetSupervisorProgramLogs retrieves the stdout log of a supervisord program, or its stderr log
+func (d *Discover) GetSupervisorProgramLogs(name string, stderr bool) string {
+	return supervisor.GetProgramLogs(name, stderr)
+}
+
+// RestartSupervisorProgram restarts a supervisord program
+func (d *Discover) RestartSupervisorProgram(name string) error {
+	return supervisor.RestartProgram(name)
+}
+
+// GetPM2Apps returns the current user's PM2 apps with their state, uptime, restarts, CPU and memory
+func (d *Discover) GetPM2Apps() ([]models.ManagedProgram, error) {
+	return pm2.GetPM2Apps()
+}
+
+// GetPM2AppLogs retrieves the stdout log of a PM2 app, or its stderr log
+func (d *Discover) GetPM2AppLogs(app models.ManagedProgram, stderr bool) string {
+	return pm2.GetAppLogs(app, stderr)
+}
+
+// RestartPM2App restarts a PM2 app
+func (d *Discover) RestartPM2App(app models.ManagedProgram) error {
+	return pm2.RestartApp(app)
+}
+
+// GetCronJobs returns the jobs of the system and user crontabs with their next run and recent executions
+func (d *Discover) GetCronJobs() []models.CronJob {
+	return cron.GetCronJobs()
+}
+
+// GetAtJobs returns the jobs queued with at
+func (d *Discover) GetAtJobs() ([]models.AtJob, error) {
+	return cron.GetAtJobs()
+}
+
+// GetVirtualMachines returns the libvirt domains with their state, vCPUs, memory and autostart setting
+func (d *Discover) GetVirtualMachines() ([]models.VirtualMachine, error) {
+	return libvirt.GetVirtualMachines()
+}
+
+// GetVirtualMachineConsoleLog returns the last lines of a domain's console log
+func (d *Discover) GetVirtualMachineConsoleLog(name string) string {
+	return libvirt.GetConsoleLog(name)
+}
+
+// ControlVirtualMachine starts, shuts down or reboots a domain (see the libvirt.Action constants)
+func (d *Discover) ControlVirtualMachine(name, action string) error {
+	return libvirt.ControlVirtualMachine(name, action)
+}
+
+// GetKernelEvents returns the OOM kills, segfaults and hung tasks the kernel logged during a boot
+// (0 for the current one, -1 for the previous one), with the resource each process belonged to
+func (d *Discover) GetKernelEvents(boot int) ([]models.KernelEvent, error) {
+	return kernel.GetKernelEvents(boot)
+}
+
+// GetListeningPorts returns the listening TCP sockets with their process, user and owning
+// systemd unit, container or pod, ordered by port
+func (d *Discover) GetListeningPorts() ([]models.ListeningSocket, error) {
+	return ports.GetListeningPorts()
+}
+
+// GetProcesses returns the user-space processes with their CPU usage, memory, zombie and runaway
+// flags, and the unit, container or pod they belong to
+func (d *Discover) GetProcesses() ([]models.Process, error) {
+	return processes.GetProcesses()
+}
+
+// GetProcessGroups returns the processes grouped by the unit, container or pod they belong to
+func (d *Discover) GetProcessGroups() ([]models.ProcessGroup, error) {
+	list, err := processes.GetProcesses()
+	if err != nil {
+		return nil, err
+	}
+	return processes.GroupByOwner(list), nil
+}
+
+// GetOwnerLogs retrieves the recent logs of the unit, container or pod that owns a port or
+// kernel event
+func (d *Discover) GetOwnerLogs(owner models.CgroupOwner) string {
+	return cgroup.Logs(owner)
+}
+
+// LoadStateFromFile loads system state from the state file, which holds the latest snapshot
 func (d *Discover) LoadStateFromFile() error {
 	loadedState, err := state.LoadState()
 	if err != nil {
@@ -101,7 +371,23 @@ func (d *Discover) LoadStateFromFile() error {
 	return nil
 }
 
-// SaveStateToFile saves the current state to the state file
+// ListSnapshots returns the stored state snapshots, newest first
+func (d *Discover) ListSnapshots() ([]models.StateSnapshot, error) {
+	return state.ListSnapshots()
+}
+
+// LoadSnapshot loads the state snapshot with the given ID
+func (d *Discover) LoadSnapshot(id string) error {
+	snapshot, err := state.LoadSnapshot(id)
+	if err != nil {
+		return err
+	}
+	
+	d.State = snapshot
+	return nil
+}
+
+// SaveStateToFile saves the current state to the state file without taking a snapshot
 func (d *Discover) SaveStateToFile() error {
 	return state.SaveState(d.State)
 }
\ No newline at end of file
@@ -2,6 +2,8 @@ module github.com/shellcanary/discover
 
 go 1.19
 
+require github.com/godbus/dbus/v5 v5.1.0
+
 require (
 	github.com/chzyer/readline v0.0.0-20180603132655-2972be24d48e // indirect
 	golang.org/x/sys v0.30.0 // indirect
@@ -14,6 +14,38 @@ type SystemdServiceDetail struct {
 	ExecMainStatus string
 	Type           string
 	Restart        string
+	// Resource accounting, zero when accounting is off or the service is not running
+	MemoryCurrent uint64 `json:",omitempty"`
+	MemoryPeak    uint64 `json:",omitempty"`
+	// CPUUsageNSec is the CPU time consumed by the service, in nanoseconds
+	CPUUsageNSec uint64 `json:",omitempty"`
+	TasksCurrent uint64 `json:",omitempty"`
+	IOReadBytes  uint64 `json:",omitempty"`
+	IOWriteBytes uint64 `json:",omitempty"`
+	NRestarts    int
+	// ActiveEnterTimestamp is when the service last became active
+	ActiveEnterTimestamp time.Time
+	// ExecMainStartTimestamp is when the current main process was started
+	ExecMainStartTimestamp time.Time
+	FragmentPath           string
+	DropInPaths            []string `json:",omitempty"`
+	// User is the user the service runs as, empty for root
+	User string `json:",omitempty"`
+	// Environment holds the service's KEY=value assignments with secret values redacted
+	Environment []string `json:",omitempty"`
+}
+
+// SystemdActionResult reports the state a unit reached after a control action
+type SystemdActionResult struct {
+	Unit   string
+	Action string
+	// Expected describes the state the action should lead to, e.g. "active" or "enabled"
+	Expected      string
+	ActiveState   string
+	SubState      string
+	UnitFileState string
+	// Reached is set when the unit ended up in the expected state
+	Reached bool
 }
 
 // DockerProject represents a Docker Compose project
@@ -34,16 +66,122 @@ type ContainerInfo struct {
 
 // KubernetesDeployment represents a deployment in Kubernetes
 type KubernetesDeployment struct {
-	Name     string
-	Replicas int
-	Ready    int
-	Status   string
+	Name        string
+	Namespace   string
+	Replicas    int
+	Ready       int
+	Status      string
+	PodLabels   map[string]string
+	Services    []string
+	Ingresses   []string
+	HelmRelease string
+	Usage       []KubernetesContainerUsage
+	// NearMemoryLimit is set when any container is close to its memory limit and at risk of an OOM kill
+	NearMemoryLimit bool
+}
+
+// KubernetesContainerUsage represents a container's current usage from the metrics API
+// alongside its requests and limits. CPU is in millicores and memory in bytes; zero means unset.
+type KubernetesContainerUsage struct {
+	Pod           string
+	Container     string
+	CPUUsage      int64
+	CPURequest    int64
+	CPULimit      int64
+	MemoryUsage   int64
+	MemoryRequest int64
+	MemoryLimit   int64
+}
+
+// KubernetesNodeUsage represents a node's current usage from the metrics API alongside its allocatable capacity
+type KubernetesNodeUsage struct {
+	Name              string
+	CPUUsage          int64
+	CPUAllocatable    int64
+	MemoryUsage       int64
+	MemoryAllocatable int64
+}
+
+// KubernetesServicePort represents a port exposed by a Kubernetes service
+type KubernetesServicePort struct {
+	Name       string
+	Protocol   string
+	Port       int
+	TargetPort string
+	NodePort   int
+}
+
+// KubernetesService represents a service in Kubernetes along with its endpoint readiness
+type KubernetesService struct {
+	Name              string
+	Type              string
+	ClusterIP         string
+	Ports             []KubernetesServicePort
+	Selector          map[string]string
+	ReadyEndpoints    []string
+	NotReadyEndpoints []string
+}
+
+// KubernetesIngressRule represents a single host/path routed to a backend service
+type KubernetesIngressRule struct {
+	Host        string
+	Path        string
+	ServiceName string
+	ServicePort string
+}
+
+// KubernetesIngress represents an ingress in Kubernetes
+type KubernetesIngress struct {
+	Name      string
+	ClassName string
+	Rules     []KubernetesIngressRule
+}
+
+// HelmRelease represents the latest revision of a Helm release installed in a namespace
+type HelmRelease struct {
+	Name         string
+	Namespace    string
+	Chart        string
+	ChartVersion string
+	AppVersion   string
+	Status       string
+	Revision     int
+	LastDeployed time.Time
+	Deployments  []string
+}
+
+// KubernetesResourceKind identifies an extra Kubernetes resource kind by group, version and kind
+type KubernetesResourceKind struct {
+	Group   string `json:"group"`
+	Version string `json:"version"`
+	Kind    string `json:"kind"`
+}
+
+// KubernetesCondition represents an entry of a resource's status.conditions
+type KubernetesCondition struct {
+	Type    string
+	Status  string
+	Reason  string
+	Message string
+}
+
+// KubernetesCustomResource represents a custom resource with a uniform health derived from its conditions
+type KubernetesCustomResource struct {
+	Name       string
+	Namespace  string
+	Kind       KubernetesResourceKind
+	Health     string
+	Conditions []KubernetesCondition
 }
 
 // KubernetesNamespace represents a namespace in Kubernetes
 type KubernetesNamespace struct {
-	Name        string
-	Deployments []KubernetesDeployment
+	Name            string
+	Deployments     []KubernetesDeployment
+	Services        []KubernetesService
+	Ingresses       []KubernetesIngress
+	HelmReleases    []HelmRelease
+	CustomResources []KubernetesCustomResource
 }
 
 // KubernetesConfig represents a Kubernetes configuration
@@ -52,6 +190,7 @@ type KubernetesConfig struct {
 	Status     string
 	Nodes      string
 	Namespaces []KubernetesNamespace
+	NodeUsage  []KubernetesNodeUsage
 }
 
 // SystemdService represents a systemd service
@@ -60,16 +199,370 @@ type SystemdService struct {
 	Status      string
 	SubStatus   string
 	Description string
+	// Scope is "system" for the system manager or "user" for a user manager; empty means system
+	Scope string `json:",omitempty"`
+	// User owns the user manager the service runs under, for user scope
+	User string `json:",omitempty"`
+	// Detail is captured for the services marked important in the config
+	Detail *SystemdServiceDetail `json:",omitempty"`
+}
+
+// SystemdTimer represents a systemd timer and the service it activates
+type SystemdTimer struct {
+	Name        string
+	Status      string
+	Description string
+	Scope       string `json:",omitempty"`
+	User        string `json:",omitempty"`
+	// Unit is the service the timer activates
+	Unit string
+	// NextElapse is zero when the timer is not scheduled to fire again
+	NextElapse time.Time
+	// LastTrigger is zero when the timer has never fired
+	LastTrigger time.Time
+	// LastResult is the Result of the activated service's last run, e.g. "success" or "exit-code"
+	LastResult string
+}
+
+// SystemdSocket represents a systemd socket unit and the service it activates
+type SystemdSocket struct {
+	Name        string
+	Status      string
+	Description string
+	Scope       string `json:",omitempty"`
+	User        string `json:",omitempty"`
+	// Listen holds the listen addresses, e.g. "[::]:22 (Stream)"
+	Listen []string
+	// Unit is the service activated by connections
+	Unit string
+}
+
+// SystemdMount represents a systemd mount unit
+type SystemdMount struct {
+	Name        string
+	Status      string
+	Description string
+	Scope       string `json:",omitempty"`
+	User        string `json:",omitempty"`
+	Where       string
+	What        string
+	Type        string
+}
+
+// SystemdTarget represents a systemd target unit
+type SystemdTarget struct {
+	Name        string
+	Status      string
+	Description string
+	Scope       string `json:",omitempty"`
+	User        string `json:",omitempty"`
+}
+
+// CronJob is a job from a system or user crontab
+type CronJob struct {
+	// Source is the crontab file the job was read from, or "crontab -l"
+	Source   string
+	User     string
+	Schedule string
+	Command  string
+	// NextRun is zero for @reboot jobs and invalid schedules
+	NextRun time.Time
+	// Runs are the most recent executions found in the journal or syslog, oldest first
+	Runs []time.Time `json:",omitempty"`
+}
+
+// AtJob is a one-off job queued with at
+type AtJob struct {
+	ID    string
+	RunAt time.Time
+	Queue string
+	User  string
+}
+
+// SystemdFailedUnit describes a unit in the failed state for triage
+type SystemdFailedUnit struct {
+	Name        string
+	Description string
+	Scope       string `json:",omitempty"`
+	User        string `json:",omitempty"`
+	// Result is systemd's reason for the failure, e.g. "exit-code", "signal" or "timeout"
+	Result string
+	// ExitStatus describes how the main process ended, e.g. "exit code 1" or "signal 9 (killed)"
+	ExitStatus string `json:",omitempty"`
+	NRestarts  int
+	FailedAt   time.Time
+	// Journal holds the last journal lines up to the failure
+	Journal []string
+}
+
+// SystemdDependency is an edge of the unit dependency graph
+type SystemdDependency struct {
+	From string
+	To   string
+	// Type is the dependency property on From, e.g. "Requires", "Wants", "After" or "PartOf"
+	Type string
+}
+
+// SystemdDependencyGraph holds the dependencies between the units of a manager
+type SystemdDependencyGraph struct {
+	// States maps the units in the graph to their active state, e.g. "active" or "failed"
+	States map[string]string
+	Edges  []SystemdDependency
+}
+
+// JournalEntry is a structured record read from the systemd journal
+type JournalEntry struct {
+	Timestamp time.Time
+	// Priority is the syslog priority, from 0 (emerg) to 7 (debug)
+	Priority   int
+	PID        int
+	BootID     string
+	Unit       string
+	Identifier string
+	Message    string
+	// Fields holds the extra journal fields that were asked for, e.g. "_COMM" or "_EXE"
+	Fields map[string]string `json:",omitempty"`
+}
+
+// JournalBoot is a boot recorded in the journal
+type JournalBoot struct {
+	// Offset is relative to the current boot: 0 is the current boot, -1 the one before it
+	Offset int
+	ID     string
+	First  time.Time
+	Last   time.Time
+}
+
+// BootAnalysis is the boot performance of one boot, as reported by systemd-analyze
+type BootAnalysis struct {
+	BootID   string    `json:"boot_id"`
+	Captured time.Time `json:"captured"`
+	// Time spent in each boot phase, zero for phases that were not reported (e.g. firmware in VMs)
+	Firmware  time.Duration `json:"firmware,omitempty"`
+	Loader    time.Duration `json:"loader,omitempty"`
+	Kernel    time.Duration `json:"kernel,omitempty"`
+	Initrd    time.Duration `json:"initrd,omitempty"`
+	Userspace time.Duration `json:"userspace,omitempty"`
+	Total     time.Duration `json:"total"`
+	// Blame lists the time each unit took to activate, slowest first
+	Blame []UnitTiming `json:"blame,omitempty"`
+	// CriticalChain lists the units on the critical path to the default target, from the target down
+	CriticalChain []CriticalChainUnit `json:"critical_chain,omitempty"`
+}
+
+// UnitTiming is the time a unit took to activate during boot
+type UnitTiming struct {
+	Unit string        `json:"unit"`
+	Time time.Duration `json:"time"`
+}
+
+// CriticalChainUnit is a unit on the boot critical chain
+type CriticalChainUnit struct {
+	Unit string `json:"unit"`
+	// ActiveAt is when the unit became active, relative to the start of userspace
+	ActiveAt time.Duration `json:"active_at"`
+	// Took is how long the unit took to start, zero when not reported
+	Took time.Duration `json:"took,omitempty"`
+}
+
+// BootUnitChange compares a unit's activation time between two boots
+type BootUnitChange struct {
+	Unit     string
+	Previous time.Duration
+	Current  time.Duration
+}
+
+// CgroupOwner identifies the resource a process belongs to, worked out from its cgroup
+type CgroupOwner struct {
+	// Kind is "systemd", "docker" or "kubernetes", or empty when no known resource owns the cgroup
+	Kind string
+	// Name is the unit name, the container name, or "namespace/pod" for Kubernetes
+	Name string
+	// ID is the container ID, or the pod UID for Kubernetes
+	ID string `json:",omitempty"`
+	// Scope and User tell system units from user units, like on SystemdService
+	Scope  string `json:",omitempty"`
+	User   string `json:",omitempty"`
+	Cgroup string
+}
+
+// KernelEvent is a notable kernel log message: an OOM kill, a segfault or a hung task
+type KernelEvent struct {
+	Timestamp time.Time
+	// Kind is "oom-kill", "segfault" or "hung-task"
+	Kind    string
+	PID     int
+	Process string
+	Message string
+	// Owner is the resource the process belonged to, when it could be worked out
+	Owner CgroupOwner
+}
+
+// ListeningSocket is a TCP socket in the listening state and the process that holds it open
+type ListeningSocket struct {
+	// Protocol is "tcp" or "tcp6"
+	Protocol string
+	Address  string
+	Port     int
+	Inode    uint64
+	// PID is 0 when the owning process could not be found, usually because it belongs to
+	// another user and we are not root
+	PID     int
+	Process string
+	User    string
+	Owner   CgroupOwner
+	// OtherNamespace is set for sockets in another network namespace than discover's, such as a
+	// container's or pod's, which are not reachable on the host's own addresses
+	OtherNamespace bool
+}
+
+// Process is a process read from /proc
+type Process struct {
+	PID     int
+	PPID    int
+	Name    string
+	Command string
+	User    string
+	// State is the /proc state letter: R running, S sleeping, D uninterruptible, Z zombie...
+	State string
+	// CPUPercent is the share of one CPU used while sampling, so it can exceed 100 for threaded processes
+	CPUPercent  float64
+	MemoryBytes uint64
+	Threads     int
+	Started     time.Time
+	Zombie      bool
+	// Runaway is set for processes using more CPU or memory than the process agent allows
+	Runaway bool
+	Owner   CgroupOwner
+}
+
+// ProcessGroup is the set of processes that belong to one unit, container or pod
+type ProcessGroup struct {
+	Owner       CgroupOwner
+	Processes   []Process
+	CPUPercent  float64
+	MemoryBytes uint64
+}
+
+// RuntimeContainer is a container run by containerd, either through CRI (Kubernetes) or nerdctl
+type RuntimeContainer struct {
+	ID    string
+	Name  string
+	Image string
+	// State is e.g. "running" or "exited"
+	State string
+	// Namespace is the containerd namespace; CRI containers live in "k8s.io"
+	Namespace string
+	Created   time.Time
+	// PodSandboxID, PodName and PodNamespace are set for CRI containers
+	PodSandboxID string `json:",omitempty"`
+	PodName      string `json:",omitempty"`
+	PodNamespace string `json:",omitempty"`
+	// Attempt is how many times the kubelet restarted the container
+	Attempt int `json:",omitempty"`
+	// LogPath is the CRI log file, filled in when logs are read
+	LogPath string `json:",omitempty"`
+}
+
+// RuntimeImage is an image stored by containerd
+type RuntimeImage struct {
+	ID   string
+	Tags []string
+	// Size is in bytes, 0 when the runtime only reports a rounded size
+	Size      uint64
+	Namespace string
+}
+
+// PodSandbox is a CRI pod sandbox: the pod-level environment its containers share
+type PodSandbox struct {
+	ID        string
+	Name      string
+	Namespace string
+	UID       string
+	// State is "ready" or "notready"
+	State   string
+	Created time.Time
+}
+
+// ManagedProgram is a program run by a process manager such as supervisord or PM2
+type ManagedProgram struct {
+	// Manager is "supervisord" or "pm2"
+	Manager string
+	Name    string
+	// ID is the PM2 process ID; supervisord programs are addressed by name
+	ID string `json:",omitempty"`
+	// State is the manager's state, e.g. "RUNNING" or "FATAL" for supervisord and "online" or "errored" for PM2
+	State  string
+	PID    int
+	Uptime time.Duration
+	// Restarts counts restarts by the manager; for supervisord only those in its recent log are seen,
+	// and none for processes whose name is shared with another group
+	Restarts int
+	// Description is supervisord's status text, e.g. "Exited too quickly (process log may have details)"
+	Description string  `json:",omitempty"`
+	CPUPercent  float64 `json:",omitempty"`
+	MemoryBytes uint64  `json:",omitempty"`
+	OutLog      string  `json:",omitempty"`
+	ErrLog      string  `json:",omitempty"`
+}
+
+// VirtualMachine is a libvirt domain
+type VirtualMachine struct {
+	Name string
+	UUID string
+	// ID is the hypervisor ID of a running domain, empty otherwise
+	ID string `json:",omitempty"`
+	// State is the virsh state, e.g. "running", "shut off" or "paused"
+	State      string
+	VCPUs      int
+	MaxMemory  uint64
+	UsedMemory uint64
+	Autostart  bool
+	Persistent bool
+}
+
+// HostFacts describes the machine the state was captured on
+type HostFacts struct {
+	Hostname string
+	// OS is the PRETTY_NAME of os-release, e.g. "Debian GNU/Linux 12 (bookworm)"
+	OS              string
+	OSID            string
+	OSVersion       string
+	Kernel          string
+	Architecture    string
+	Uptime          time.Duration
+	Load1           float64
+	Load5           float64
+	Load15          float64
+	CPUs            int
+	MemoryTotal     uint64
+	MemoryAvailable uint64
+	SwapTotal       uint64
+	SwapFree        uint64
+	Filesystems     []FilesystemUsage
+	Captured        time.Time
+}
+
+// FilesystemUsage is the space and inode usage of a mounted filesystem
+type FilesystemUsage struct {
+	Mount      string
+	Device     string
+	Type       string
+	Size       uint64
+	Used       uint64
+	Available  uint64
+	Inodes     uint64
+	InodesUsed uint64
 }
 
 // LogEntry represents a log entry in the state file
 type LogEntry struct {
-	DataType    string    `json:"data_type"`
-	Project     string    `json:"project"`
-	Container   string    `json:"container,omitempty"`
-	Deployment  string    `json:"deployment,omitempty"`
-	LogContent  string    `json:"log_content"`
-	Timestamp   time.Time `json:"timestamp"`
+	DataType   string    `json:"data_type"`
+	Project    string    `json:"project"`
+	Container  string    `json:"container,omitempty"`
+	Deployment string    `json:"deployment,omitempty"`
+	LogContent string    `json:"log_content"`
+	Timestamp  time.Time `json:"timestamp"`
 }
 
 // SystemState represents the entire system state
@@ -77,5 +570,18 @@ type SystemState struct {
 	DockerProjects    []DockerProject    `json:"docker_compose_projects"`
 	KubernetesConfigs []KubernetesConfig `json:"kubernetes_projects"`
 	SystemdServices   []SystemdService   `json:"systemd_services,omitempty"`
+	Boots             []BootAnalysis     `json:"boots,omitempty"`
+	Host              *HostFacts         `json:"host,omitempty"`
 	LastUpdated       time.Time          `json:"last_updated"`
-}
\ No newline at end of file
+	// SnapshotID identifies the snapshot the state was captured as; it is cleared once a partial
+	// update is saved over it
+	SnapshotID string `json:"snapshot_id,omitempty"`
+}
+
+// StateSnapshot describes a stored snapshot of the system state
+type StateSnapshot struct {
+	ID    string
+	Taken time.Time
+	Path  string
+	Size  int64
+}
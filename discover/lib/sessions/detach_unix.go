@@ -0,0 +1,13 @@
+//go:build unix
+
+package sessions
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// detach runs the command in its own process group
+func detach(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
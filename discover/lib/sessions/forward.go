@@ -0,0 +1,75 @@
+package sessions
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/signal"
+	"strconv"
+	"sync"
+)
+
+// ForwardTCP listens on localhost:localPort and relays each connection to remoteAddr.
+// It returns a function that stops the forward and a channel closed once it has stopped.
+func ForwardTCP(localPort int, remoteAddr string) (func() error, <-chan struct{}, error) {
+	listener, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(localPort)))
+	if err != nil {
+		return nil, nil, fmt.Errorf("error listening on port %d: %v", localPort, err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		var connections sync.WaitGroup
+		for {
+			local, err := listener.Accept()
+			if err != nil {
+				// The listener was closed
+				break
+			}
+			connections.Add(1)
+			go func() {
+				defer connections.Done()
+				relay(local, remoteAddr)
+			}()
+		}
+		connections.Wait()
+	}()
+
+	return listener.Close, done, nil
+}
+
+// relay copies data in both directions between a local connection and the remote address
+func relay(local net.Conn, remoteAddr string) {
+	defer local.Close()
+
+	remote, err := net.Dial("tcp", remoteAddr)
+	if err != nil {
+		fmt.Printf("Warning: Could not connect to %s: %v\n", remoteAddr, err)
+		return
+	}
+	defer remote.Close()
+
+	copied := make(chan struct{}, 2)
+	go func() {
+		io.Copy(remote, local)
+		copied <- struct{}{}
+	}()
+	go func() {
+		io.Copy(local, remote)
+		copied <- struct{}{}
+	}()
+
+	// Stop relaying as soon as either side hangs up
+	<-copied
+}
+
+// WaitForInterrupt blocks until the user presses Ctrl+C
+func WaitForInterrupt() {
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+	defer signal.Stop(interrupts)
+
+	<-interrupts
+}
@@ -0,0 +1,209 @@
+package sessions
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Session represents a port-forward kept running in the background
+type Session struct {
+	ID         int
+	Kind       string
+	Target     string
+	LocalPort  int
+	RemotePort int
+	Started    time.Time
+
+	stop func() error
+	done <-chan struct{}
+}
+
+var (
+	registry = make(map[int]*Session)
+	nextID   = 1
+	lock     sync.Mutex
+)
+
+// Register tracks a background session. stop ends the session and done is closed once it has exited.
+func Register(kind, target string, localPort, remotePort int, stop func() error, done <-chan struct{}) Session {
+	lock.Lock()
+	defer lock.Unlock()
+
+	session := &Session{
+		ID:         nextID,
+		Kind:       kind,
+		Target:     target,
+		LocalPort:  localPort,
+		RemotePort: remotePort,
+		Started:    time.Now(),
+		stop:       stop,
+		done:       done,
+	}
+	registry[session.ID] = session
+	nextID++
+
+	return *session
+}
+
+// List returns the sessions that are still running, oldest first
+func List() []Session {
+	lock.Lock()
+	defer lock.Unlock()
+
+	var sessions []Session
+	for id, session := range registry {
+		select {
+		case <-session.done:
+			// Forget sessions that exited on their own, e.g. when the pod went away
+			delete(registry, id)
+		default:
+			sessions = append(sessions, *session)
+		}
+	}
+
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].ID < sessions[j].ID })
+	return sessions
+}
+
+// Stop ends a background session
+func Stop(id int) error {
+	lock.Lock()
+	session, exists := registry[id]
+	delete(registry, id)
+	lock.Unlock()
+
+	if !exists {
+		return fmt.Errorf("no session with ID %d", id)
+	}
+	return session.stop()
+}
+
+// StopAll ends every background session, used when the application exits
+func StopAll() {
+	for _, session := range List() {
+		if err := Stop(session.ID); err != nil {
+			fmt.Printf("Warning: Failed to stop session %d: %v\n", session.ID, err)
+		}
+	}
+}
+
+// RunInteractive hands the terminal to a command until it exits. Interrupts are left
+// to the command, so Ctrl+C ends the session instead of the whole application.
+func RunInteractive(cmd *exec.Cmd) error {
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+	defer signal.Stop(interrupts)
+
+	return cmd.Run()
+}
+
+// startTimeout is how long StartBackgroundWhenReady waits for a command to get ready
+const startTimeout = 15 * time.Second
+
+// StartBackground starts a command detached from the terminal, in its own process group so
+// Ctrl+C in later interactive sessions does not reach it, and registers it as a session
+func StartBackground(cmd *exec.Cmd, kind, target string, localPort, remotePort int) (Session, error) {
+	stop, done, err := startDetached(cmd, nil)
+	if err != nil {
+		return Session{}, err
+	}
+	return Register(kind, target, localPort, remotePort, stop, done), nil
+}
+
+// StartBackgroundWhenReady starts a command like StartBackground, but only registers it once it
+// prints a line containing ready. When the command exits first, or is not ready within
+// startTimeout, it is stopped and its output returned in the error.
+func StartBackgroundWhenReady(cmd *exec.Cmd, ready, kind, target string, localPort, remotePort int) (Session, error) {
+	reader, writer := io.Pipe()
+	cmd.Stdout = writer
+	cmd.Stderr = writer
+
+	stop, done, err := startDetached(cmd, writer)
+	if err != nil {
+		return Session{}, err
+	}
+
+	// The output is only kept until the ready line; afterwards it is drained so the command
+	// does not block once the pipe is full
+	var output []string
+	readyLine := make(chan struct{})
+	outputRead := make(chan struct{})
+	go func() {
+		defer close(outputRead)
+		scanner := bufio.NewScanner(reader)
+		for scanner.Scan() {
+			output = append(output, scanner.Text())
+			if strings.Contains(scanner.Text(), ready) {
+				close(readyLine)
+				break
+			}
+		}
+		io.Copy(ioutil.Discard, reader)
+	}()
+
+	select {
+	case <-readyLine:
+		return Register(kind, target, localPort, remotePort, stop, done), nil
+	case <-done:
+		<-outputRead
+		return Session{}, startFailure("exited before it was ready", output)
+	case <-time.After(startTimeout):
+		stop()
+		<-outputRead
+		return Session{}, startFailure(fmt.Sprintf("not ready after %s", startTimeout), output)
+	}
+}
+
+// startFailure describes why a command did not get ready, along with what it printed
+func startFailure(reason string, output []string) error {
+	if len(output) == 0 {
+		return fmt.Errorf("%s", reason)
+	}
+	return fmt.Errorf("%s: %s", reason, strings.Join(output, "\n"))
+}
+
+// startDetached starts a command in its own process group. It returns a function that kills the
+// command and a channel closed once it has exited, after which output, when set, is closed.
+func startDetached(cmd *exec.Cmd, output io.Closer) (func() error, <-chan struct{}, error) {
+	detach(cmd)
+	if err := cmd.Start(); err != nil {
+		return nil, nil, err
+	}
+
+	done := make(chan struct{})
+	go func() {
+		cmd.Wait()
+		if output != nil {
+			output.Close()
+		}
+		close(done)
+	}()
+
+	stop := func() error {
+		select {
+		case <-done:
+			// Already exited
+			return nil
+		default:
+		}
+		if err := cmd.Process.Kill(); err != nil {
+			return err
+		}
+		<-done
+		return nil
+	}
+	return stop, done, nil
+}
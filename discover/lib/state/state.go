@@ -6,13 +6,25 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 
+	"github.com/shellcanary/discover/lib/config"
 	"github.com/shellcanary/discover/lib/models"
 )
 
 const stateFileName = "discover_state.json"
 
+// maxBootAnalyses is how many boots are kept in the state file
+const maxBootAnalyses = 20
+
+// snapshotDirName is the directory next to the state file that holds the snapshots
+const snapshotDirName = "snapshots"
+
+// snapshotIDFormat is the UTC timestamp that identifies a snapshot and names its file
+const snapshotIDFormat = "20060102T150405.000000000Z"
+
 // GetStateFilePath returns the path to the state file
 func GetStateFilePath() string {
 	homeDir, err := os.UserHomeDir()
@@ -33,7 +45,8 @@ func GetStateFilePath() string {
 	return filepath.Join(discoverDir, stateFileName)
 }
 
-// LoadState loads the system state from the state file
+// LoadState loads the system state from the state file, which holds the latest snapshot along
+// with any partial updates saved since. Without a state file the latest snapshot is read.
 func LoadState() (models.SystemState, error) {
 	var state models.SystemState
 	
@@ -41,6 +54,14 @@ func LoadState() (models.SystemState, error) {
 	data, err := ioutil.ReadFile(stateFile)
 	if err != nil {
 		if os.IsNotExist(err) {
+			snapshots, err := ListSnapshots()
+			if err != nil {
+				return state, err
+			}
+			if len(snapshots) > 0 {
+				return LoadSnapshot(snapshots[0].ID)
+			}
+			
 			// Return an empty state if the file doesn't exist
 			return models.SystemState{
 				LastUpdated: time.Now(),
@@ -56,10 +77,12 @@ func LoadState() (models.SystemState, error) {
 	return state, nil
 }
 
-// SaveState saves the system state to the state file
+// SaveState saves the system state to the state file without taking a snapshot. Once saved the
+// state no longer matches the snapshot it was loaded from, so its SnapshotID is cleared.
 func SaveState(state models.SystemState) error {
 	// Ensure the LastUpdated field is set to current time
 	state.LastUpdated = time.Now()
+	state.SnapshotID = ""
 	
 	data, err := json.MarshalIndent(state, "", "  ")
 	if err != nil {
@@ -74,8 +97,127 @@ func SaveState(state models.SystemState) error {
 	return nil
 }
 
+// SaveSnapshot stores a full capture of the system state as a new snapshot and returns its ID.
+// Snapshots are never rewritten; the state file is replaced with a copy of the new one.
+func SaveSnapshot(state models.SystemState) (string, error) {
+	// Ensure the LastUpdated field is set to current time
+	state.LastUpdated = time.Now()
+	state.SnapshotID = state.LastUpdated.UTC().Format(snapshotIDFormat)
+	
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("error encoding state: %v", err)
+	}
+	
+	snapshotDir := GetSnapshotDir()
+	if err := os.MkdirAll(snapshotDir, 0755); err != nil {
+		return "", fmt.Errorf("error creating snapshot directory: %v", err)
+	}
+	
+	// Read-only, and never replaces an existing snapshot
+	snapshotFile := filepath.Join(snapshotDir, state.SnapshotID+".json")
+	file, err := os.OpenFile(snapshotFile, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0444)
+	if err != nil {
+		return "", fmt.Errorf("error creating snapshot: %v", err)
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return "", fmt.Errorf("error writing snapshot: %v", err)
+	}
+	if err := file.Close(); err != nil {
+		return "", fmt.Errorf("error writing snapshot: %v", err)
+	}
+	
+	stateFile := GetStateFilePath()
+	if err := ioutil.WriteFile(stateFile, data, 0644); err != nil {
+		return state.SnapshotID, fmt.Errorf("error writing state file: %v", err)
+	}
+	
+	return state.SnapshotID, pruneSnapshots()
+}
+
+// GetSnapshotDir returns the directory holding the state snapshots
+func GetSnapshotDir() string {
+	return filepath.Join(filepath.Dir(GetStateFilePath()), snapshotDirName)
+}
+
+// ListSnapshots returns the stored snapshots, newest first
+func ListSnapshots() ([]models.StateSnapshot, error) {
+	snapshotDir := GetSnapshotDir()
+	entries, err := ioutil.ReadDir(snapshotDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error reading snapshot directory: %v", err)
+	}
+	
+	var snapshots []models.StateSnapshot
+	for _, entry := range entries {
+		id := strings.TrimSuffix(entry.Name(), ".json")
+		taken, err := time.Parse(snapshotIDFormat, id)
+		if entry.IsDir() || id == entry.Name() || err != nil {
+			continue
+		}
+		snapshots = append(snapshots, models.StateSnapshot{
+			ID:    id,
+			Taken: taken,
+			Path:  filepath.Join(snapshotDir, entry.Name()),
+			Size:  entry.Size(),
+		})
+	}
+	
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].Taken.After(snapshots[j].Taken) })
+	return snapshots, nil
+}
+
+// LoadSnapshot loads the system state stored in a snapshot
+func LoadSnapshot(id string) (models.SystemState, error) {
+	var state models.SystemState
+	
+	// IDs are timestamps, which also keeps them from pointing outside the snapshot directory
+	if _, err := time.Parse(snapshotIDFormat, id); err != nil {
+		return state, fmt.Errorf("invalid snapshot ID %q", id)
+	}
+	
+	data, err := ioutil.ReadFile(filepath.Join(GetSnapshotDir(), id+".json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return state, fmt.Errorf("snapshot %s not found", id)
+		}
+		return state, fmt.Errorf("error reading snapshot %s: %v", id, err)
+	}
+	
+	if err := json.Unmarshal(data, &state); err != nil {
+		return state, fmt.Errorf("error parsing snapshot %s: %v", id, err)
+	}
+	state.SnapshotID = id
+	
+	return state, nil
+}
+
+// pruneSnapshots removes the oldest snapshots beyond state.max_snapshots in the config, if set
+func pruneSnapshots() error {
+	maxSnapshots := config.Current().State.MaxSnapshots
+	if maxSnapshots <= 0 {
+		return nil
+	}
+	
+	snapshots, err := ListSnapshots()
+	if err != nil {
+		return err
+	}
+	
+	for i := maxSnapshots; i < len(snapshots); i++ {
+		if err := os.Remove(snapshots[i].Path); err != nil {
+			return fmt.Errorf("error removing old snapshot %s: %v", snapshots[i].ID, err)
+		}
+	}
+	return nil
+}
 
-// UpdateSystemState updates the full system state with current data
+// UpdateSystemState updates the full system state with current data. Like the other partial
+// updates it only rewrites the state file, which is then newer than the latest snapshot.
 func UpdateSystemState(
 	dockerProjects []models.DockerProject,
 	k8sConfigs []models.KubernetesConfig,
@@ -93,4 +235,59 @@ func UpdateSystemState(
 	state.SystemdServices = systemdServices
 	
 	return SaveState(state)
-}
\ No newline at end of file
+}
+
+// SaveBootAnalysis stores the analysis of a boot, replacing an earlier capture of the same boot
+// and keeping only the most recent boots
+func SaveBootAnalysis(analysis models.BootAnalysis) error {
+	state, err := LoadState()
+	if err != nil {
+		return err
+	}
+	
+	AddBootAnalysis(&state, analysis)
+	return SaveState(state)
+}
+
+// AddBootAnalysis adds the analysis of a boot to a state without saving it, replacing an earlier
+// capture of the same boot and keeping only the most recent boots
+func AddBootAnalysis(state *models.SystemState, analysis models.BootAnalysis) {
+	var boots []models.BootAnalysis
+	for _, boot := range state.Boots {
+		if boot.BootID != analysis.BootID {
+			boots = append(boots, boot)
+		}
+	}
+	boots = append(boots, analysis)
+	if len(boots) > maxBootAnalyses {
+		boots = boots[len(boots)-maxBootAnalyses:]
+	}
+	state.Boots = boots
+}
+
+// PreviousBootAnalysis returns the most recently captured analysis of a boot other than the given one
+func PreviousBootAnalysis(bootID string) (models.BootAnalysis, bool, error) {
+	state, err := LoadState()
+	if err != nil {
+		return models.BootAnalysis{}, false, err
+	}
+	
+	for i := len(state.Boots) - 1; i >= 0; i-- {
+		if state.Boots[i].BootID != bootID {
+			return state.Boots[i], true, nil
+		}
+	}
+	return models.BootAnalysis{}, false, nil
+}
+
+// SaveHostFacts stores the facts of the machine the state describes in the state file, without
+// taking a snapshot
+func SaveHostFacts(facts models.HostFacts) error {
+	state, err := LoadState()
+	if err != nil {
+		return err
+	}
+	
+	state.Host = &facts
+	return SaveState(state)
+}
@@ -4,39 +4,101 @@ import (
 	"fmt"
 	"os"
 
+	"discover/agents/systemd"
+	"discover/config"
 	"discover/ui"
 	"discover/ui/help"
+	"discover/ui/systemd"
 )
 
 func main() {
-	// Check command line args
-	if len(os.Args) > 1 {
-		// Process command line flags
-		switch os.Args[1] {
+	// Start from the config file settings, which command line flags add to
+	cfg := config.Current()
+	captureState := false
+	failedReport := false
+
+	// Process command line flags
+	args := os.Args[1:]
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
 		case "--help", "-h":
 			// Show help info and exit
 			help.ShowHelpPage()
 			os.Exit(0)
-			
+
 		case "--capture-state":
-			// Capture system state and exit
-			if err := ui.CaptureSystemState(); err != nil {
-				fmt.Println(err)
+			// Capture system state after all flags are applied
+			captureState = true
+
+		case "--failed":
+			// Print the failed units report after all flags are applied
+			failedReport = true
+
+		case "--context", "--exclude-context", "--namespace", "--exclude-namespace":
+			// Kubernetes filter flags take a glob pattern
+			if i+1 >= len(args) {
+				fmt.Printf("Option %s requires a pattern\n\n", args[i])
+				showUsage()
 				os.Exit(1)
 			}
-			os.Exit(0)
-			
+			pattern := args[i+1]
+			switch args[i] {
+			case "--context":
+				cfg.Kubernetes.IncludeContexts = append(cfg.Kubernetes.IncludeContexts, pattern)
+			case "--exclude-context":
+				cfg.Kubernetes.ExcludeContexts = append(cfg.Kubernetes.ExcludeContexts, pattern)
+			case "--namespace":
+				cfg.Kubernetes.IncludeNamespaces = append(cfg.Kubernetes.IncludeNamespaces, pattern)
+			case "--exclude-namespace":
+				cfg.Kubernetes.ExcludeNamespaces = append(cfg.Kubernetes.ExcludeNamespaces, pattern)
+			}
+			i++
+
+		case "--active-context-only":
+			cfg.Kubernetes.ActiveContextOnly = true
+
+		case "--all-users":
+			cfg.Systemd.AllUsers = true
+
 		default:
 			// Unknown flag, show brief usage and exit
-			fmt.Printf("Unknown option: %s\n\n", os.Args[1])
-			fmt.Println("Usage: discover [OPTION]")
-			fmt.Println("  --help, -h          Display help information")
-			fmt.Println("  --capture-state     Capture current system state")
-			fmt.Println("\nRun without arguments for interactive mode.")
+			fmt.Printf("Unknown option: %s\n\n", args[i])
+			showUsage()
 			os.Exit(1)
 		}
 	}
-	
+	config.Set(cfg)
+
+	if failedReport {
+		// Print the failed units report and exit
+		systemdUI.PrintFailedUnitsReport(systemd.GetFailedUnits())
+		os.Exit(0)
+	}
+
+	if captureState {
+		// Capture system state and exit
+		if err := ui.CaptureSystemState(); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
 	// Start the interactive menu system
 	ui.StartMainMenu()
-}
\ No newline at end of file
+}
+
+// showUsage prints a brief summary of the command line options
+func showUsage() {
+	fmt.Println("Usage: discover [OPTION]...")
+	fmt.Println("  --help, -h                   Display help information")
+	fmt.Println("  --capture-state              Capture current system state")
+	fmt.Println("  --failed                     Print a report of failed systemd units")
+	fmt.Println("  --context PATTERN            Only probe Kubernetes contexts matching PATTERN")
+	fmt.Println("  --exclude-context PATTERN    Skip Kubernetes contexts matching PATTERN")
+	fmt.Println("  --namespace PATTERN          Only probe Kubernetes namespaces matching PATTERN")
+	fmt.Println("  --exclude-namespace PATTERN  Skip Kubernetes namespaces matching PATTERN")
+	fmt.Println("  --active-context-only        Only probe the current Kubernetes context")
+	fmt.Println("  --all-users                  As root, include user services of all logged-in users")
+	fmt.Println("\nRun without arguments for interactive mode.")
+}
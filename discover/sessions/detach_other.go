@@ -0,0 +1,8 @@
+//go:build !unix
+
+package sessions
+
+import "os/exec"
+
+// detach is a no-op on platforms without process groups
+func detach(cmd *exec.Cmd) {}
@@ -0,0 +1,155 @@
+package containerdUI
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/manifoldco/promptui"
+	"discover/agents/containerd"
+	"discover/models"
+	"discover/ui/utils"
+)
+
+// ShowContainerdMenu browses the namespaces, containers, images and pod sandboxes of containerd
+func ShowContainerdMenu() {
+	for {
+		prompt := promptui.Select{
+			Label: "🧱 Select what to browse in containerd",
+			Items: []string{"📦 Containers", "🫛 Pod Sandboxes", "🖼️ Images", "🗂️ Namespaces", "⬅️ Back"},
+		}
+		_, result, err := prompt.Run()
+		if err != nil {
+			fmt.Printf("Prompt failed %v\n", err)
+			return
+		}
+
+		switch result {
+		case "⬅️ Back":
+			return
+		case "📦 Containers":
+			showContainers()
+		case "🫛 Pod Sandboxes":
+			printSandboxes()
+		case "🖼️ Images":
+			printImages()
+		case "🗂️ Namespaces":
+			namespaces, err := containerd.GetNamespaces()
+			if err != nil {
+				fmt.Println(err)
+				continue
+			}
+			for _, namespace := range namespaces {
+				fmt.Println(namespace)
+			}
+		}
+	}
+}
+
+// showContainers lists the containers and prints the logs of the selected one
+func showContainers() {
+	containers, err := containerd.GetRuntimeContainers()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	if len(containers) == 0 {
+		fmt.Println("No containers found.")
+		return
+	}
+
+	options := []string{"⬅️ Back"}
+	for _, container := range containers {
+		options = append(options, containerLabel(container))
+	}
+	containerPrompt := promptui.Select{
+		Label: "📦 Select a container to view logs",
+		Items: options,
+		Size:  15,
+	}
+	index, result, err := containerPrompt.Run()
+	if err != nil || result == "⬅️ Back" {
+		return
+	}
+
+	container := containers[index-1]
+	printContainer(container)
+	fmt.Println(containerd.GetRuntimeContainerLogs(&container))
+}
+
+// containerLabel describes a container in one line
+func containerLabel(container models.RuntimeContainer) string {
+	icon := "⚪"
+	if container.State == "running" {
+		icon = "🟢"
+	}
+	if container.PodName != "" {
+		return fmt.Sprintf("%s %s/%s: %s (%s)", icon, container.PodNamespace, container.PodName, container.Name, container.State)
+	}
+	return fmt.Sprintf("%s [%s] %s (%s)", icon, container.Namespace, container.Name, container.State)
+}
+
+// printContainer prints the details of a container
+func printContainer(container models.RuntimeContainer) {
+	fmt.Printf("Container: %s\n", container.Name)
+	fmt.Printf("ID: %s\n", container.ID)
+	fmt.Printf("Image: %s\n", container.Image)
+	fmt.Printf("State: %s\n", container.State)
+	fmt.Printf("Namespace: %s\n", container.Namespace)
+	if !container.Created.IsZero() {
+		fmt.Printf("Created: %s\n", container.Created.Format("2006-01-02 15:04:05"))
+	}
+	if container.PodName != "" {
+		fmt.Printf("Pod: %s/%s (sandbox %s)\n", container.PodNamespace, container.PodName, shortID(container.PodSandboxID))
+		fmt.Printf("Restarts: %d\n", container.Attempt)
+	}
+	fmt.Println()
+}
+
+// printSandboxes prints the CRI pod sandboxes
+func printSandboxes() {
+	sandboxes, err := containerd.GetPodSandboxes()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	if len(sandboxes) == 0 {
+		fmt.Println("No pod sandboxes found.")
+		return
+	}
+	for _, sandbox := range sandboxes {
+		fmt.Printf("%-13s %-8s %s/%s (created %s)\n", shortID(sandbox.ID), sandbox.State, sandbox.Namespace,
+			sandbox.Name, sandbox.Created.Format("2006-01-02 15:04"))
+	}
+}
+
+// printImages prints the images of every namespace
+func printImages() {
+	images, err := containerd.GetRuntimeImages()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	if len(images) == 0 {
+		fmt.Println("No images found.")
+		return
+	}
+	for _, image := range images {
+		tags := strings.Join(image.Tags, ", ")
+		if tags == "" {
+			tags = "<untagged>"
+		}
+		size := ""
+		if image.Size > 0 {
+			size = " " + utilsUI.FormatBytes(image.Size)
+		}
+		fmt.Printf("[%s] %s %s%s\n", image.Namespace, shortID(strings.TrimPrefix(image.ID, "sha256:")), tags, size)
+	}
+}
+
+// shortID shortens a container, sandbox or image ID for display
+func shortID(id string) string {
+	if len(id) > 13 {
+		return id[:13]
+	}
+	return id
+}
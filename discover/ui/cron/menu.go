@@ -0,0 +1,125 @@
+package cronUI
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/manifoldco/promptui"
+	"discover/agents/cron"
+	"discover/models"
+	"discover/ui/utils"
+)
+
+// ShowCronMenu lists cron jobs by their next run and shows the details and recent executions
+// of the selected job; at jobs are listed from their own entry
+func ShowCronMenu(jobs []models.CronJob) {
+	sorted := append([]models.CronJob{}, jobs...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		// Jobs that never run on a schedule (@reboot) go last
+		if sorted[i].NextRun.IsZero() != sorted[j].NextRun.IsZero() {
+			return !sorted[i].NextRun.IsZero()
+		}
+		return sorted[i].NextRun.Before(sorted[j].NextRun)
+	})
+
+	options := []string{"⬅️ Back", "📌 At Jobs"}
+	for _, job := range sorted {
+		options = append(options, fmt.Sprintf("🕐 %-15s %-8s %s (next: %s)", job.Schedule, job.User,
+			truncate(job.Command, 50), nextRun(job)))
+	}
+
+	for {
+		jobPrompt := promptui.Select{
+			Label: fmt.Sprintf("🕐 %d cron job(s), select one for details", len(sorted)),
+			Items: options,
+			Size:  15,
+		}
+		index, result, err := jobPrompt.Run()
+		if err != nil {
+			fmt.Printf("Job selection failed: %v\n", err)
+			return
+		}
+
+		switch result {
+		case "⬅️ Back":
+			return
+		case "📌 At Jobs":
+			showAtJobs()
+		default:
+			printJob(sorted[index-2])
+		}
+	}
+}
+
+// printJob prints the details of a cron job and when it last ran
+func printJob(job models.CronJob) {
+	fmt.Printf("Command:  %s\n", job.Command)
+	fmt.Printf("Schedule: %s\n", job.Schedule)
+	fmt.Printf("User:     %s\n", job.User)
+	fmt.Printf("Source:   %s\n", job.Source)
+	fmt.Printf("Next run: %s\n", nextRun(job))
+
+	if len(job.Runs) == 0 {
+		fmt.Println("Recent runs: none found in the journal or syslog")
+		return
+	}
+	fmt.Println("Recent runs:")
+	for i := len(job.Runs) - 1; i >= 0; i-- {
+		fmt.Printf("  %s\n", utilsUI.FormatTime(job.Runs[i]))
+	}
+}
+
+// showAtJobs lists the queued at jobs and prints the script of the selected one
+func showAtJobs() {
+	jobs, err := cron.GetAtJobs()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	if len(jobs) == 0 {
+		fmt.Println("No at jobs queued.")
+		return
+	}
+
+	options := []string{"⬅️ Back"}
+	for _, job := range jobs {
+		options = append(options, fmt.Sprintf("📌 %s  %s  queue %s  %s", job.ID, utilsUI.FormatTime(job.RunAt), job.Queue, job.User))
+	}
+	jobPrompt := promptui.Select{
+		Label: "📌 Select an at job to see its script",
+		Items: options,
+		Size:  15,
+	}
+	index, result, err := jobPrompt.Run()
+	if err != nil || result == "⬅️ Back" {
+		return
+	}
+
+	script, err := cron.GetAtJobScript(jobs[index-1].ID)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(script)
+}
+
+// nextRun describes when a job runs next
+func nextRun(job models.CronJob) string {
+	if job.Schedule == "@reboot" {
+		return "at boot"
+	}
+	if job.NextRun.IsZero() {
+		// Invalid, or impossible like February 30th
+		return "never"
+	}
+	return utilsUI.FormatTime(job.NextRun)
+}
+
+// truncate shortens text to at most n characters
+func truncate(text string, n int) string {
+	runes := []rune(text)
+	if len(runes) <= n {
+		return text
+	}
+	return string(runes[:n-3]) + "..."
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/manifoldco/promptui"
 	"discover/agents/docker"
+	"discover/ui/sessions"
 )
 
 // ShowDockerMenu handles the Docker project menu
@@ -43,8 +44,44 @@ func ShowDockerMenu(projectName string) {
 		logs = docker.GetAllProjectLogs(projectName)
 		fmt.Println(logs)
 	} else {
+		showContainerMenu(projectName, containerSelection)
+	}
+}
+
+// showContainerMenu handles the actions available for a single container
+func showContainerMenu(projectName, containerName string) {
+	actionPrompt := promptui.Select{
+		Label: fmt.Sprintf("🔍 Select an action for container '%s'", containerName),
+		Items: []string{"📜 View Logs", "🐚 Open Shell", "🔌 Port Forward", "⬅️ Back"},
+	}
+	
+	_, actionSelection, err := actionPrompt.Run()
+	if err != nil {
+		fmt.Printf("Action selection failed: %v\n", err)
+		return
+	}
+	
+	switch actionSelection {
+	case "📜 View Logs":
 		// Get logs for the selected container
-		logs = docker.GetDockerLogs(projectName, containerSelection)
+		logs := docker.GetDockerLogs(projectName, containerName)
 		fmt.Println(logs)
+		
+	case "🐚 Open Shell":
+		if err := docker.OpenContainerShell(projectName, containerName); err != nil {
+			fmt.Println(err)
+		}
+		
+	case "🔌 Port Forward":
+		localPort, remotePort, background, err := sessionsUI.PromptPortForward()
+		if err != nil {
+			fmt.Printf("Port-forward setup failed: %v\n", err)
+			return
+		}
+		if err := docker.PortForwardContainer(projectName, containerName, localPort, remotePort, background); err != nil {
+			fmt.Println(err)
+		} else if background {
+			fmt.Printf("Forwarding localhost:%d to %s port %d in the background\n", localPort, containerName, remotePort)
+		}
 	}
 }
\ No newline at end of file
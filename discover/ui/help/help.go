@@ -21,19 +21,112 @@ MAIN FUNCTIONALITY:
 • Resource Details: View detailed information about system components
 • State Capture: Save the current system state for future reference
 
+HOST SUMMARY:
+------------
+The interactive interface opens with a summary of this machine: hostname,
+OS, kernel, architecture, uptime, load, memory, and the space and inode
+usage of each filesystem (90% or fuller is highlighted). These facts are
+saved with the system state.
+
 RESOURCE TYPES:
 --------------
 🐳 Docker:
    - View Docker Compose projects and their containers
    - Access logs for specific containers or entire projects
+   - Open a shell in a container or forward a local port to it
 
 ☸️ Kubernetes:
    - Browse Kubernetes contexts, namespaces, and deployments
    - View deployment logs and status information
+   - See which services and ingresses route to a deployment and whether
+     their endpoints are ready
+   - Browse Helm releases and the deployments they own
+   - Check the health of custom resources listed in the config file
+   - Compare pod and node usage with requests and limits (needs metrics-server)
+   - Deployments with a container near its memory limit are marked ⚠️
+   - Open a shell in a deployment's pod or port-forward to it
 
 ⚙️ Systemd:
-   - List active systemd services
-   - View service logs, status details, and perform restarts
+   - List active systemd services, including your user services
+     (systemctl --user) and, as root with --all-users, those of every
+     logged-in user
+   - View service logs from the current, previous, or any recorded boot,
+     filtered by priority and colored by severity
+   - View status details, including memory, CPU, tasks
+     and IO usage, restart count, unit file and drop-ins, and the service
+     environment with secrets redacted
+   - Show what a service depends on (Requires, Wants, After, PartOf) and
+     what depends on it, with failed units marked, and export that graph
+     as DOT or Mermaid for incident docs
+   - View a service's unit file with all drop-ins (like systemctl cat) and
+     create or edit its override in $EDITOR; the override is checked with
+     systemd-analyze verify (and reverted if invalid) before the unit files
+     are reloaded
+   - Start, stop, restart, reload, enable, disable, mask, or unmask a service,
+     or reload the unit files of its manager. Each action asks for
+     confirmation and reports the state the service ended up in; if
+     authentication is required you can retry with polkit or sudo
+   - "Failed Units" in the main menu lists every failed unit with its
+     result, exit status, restart count, failure time, and the last
+     journal lines before the failure
+   - "Boot Analysis" in the main menu shows the boot time per phase, the
+     slowest units, and the critical chain, saves them per boot, and lists
+     the units that got slower since the previously captured boot
+     (--capture-state records the boot analysis too)
+   - Browse timers (next run, last run and its result), sockets, mounts,
+     and targets from "Systemd: Timers, Sockets, Mounts & Targets"
+
+🧱 Containerd & CRI:
+   - On Kubernetes nodes where containerd runs the containers, "Containerd
+     & CRI" in the main menu lists containers (with their pods), pod
+     sandboxes, images, and namespaces using crictl and nerdctl
+   - Container logs are read from the CRI log files (run as root)
+   - Set "socket" in the "containerd" section of the config file for a
+     non-default socket, e.g. /run/k3s/containerd/containerd.sock
+
+🧰 Supervisord & PM2:
+   - "Supervisord & PM2 Only" (or "All Resource Types") lists supervisord
+     programs and your PM2 apps with their state
+   - View stdout and stderr logs, details (PID, uptime, restarts, and for
+     PM2 CPU and memory), and restart a program after confirmation
+   - Supervisord restarts are counted from its recent log
+
+🕐 Cron:
+   - "Cron Only" (or "All Resource Types") finds jobs in /etc/crontab,
+     /etc/cron.d, and user crontabs (every user's as root, otherwise
+     yours), with their next run and the recent runs cron logged to the
+     journal or syslog
+   - "At Jobs" lists jobs queued with at and shows their scripts
+
+🐧 Kernel:
+   - "Kernel Events" in the main menu lists OOM kills, segfaults and hung
+     tasks from the kernel log of the current or previous boot, with the
+     systemd unit, Docker container or Kubernetes pod the process ran in
+
+🔊 Ports:
+   - "Listening Ports" in the main menu lists listening TCP ports with the
+     process, user, and systemd unit, container, or pod that owns them; use
+     "Find Port" to answer "what is listening on 8080?" and open the
+     owner's logs from the port (run as root to see every process)
+
+🧬 Processes:
+   - "Processes" in the main menu shows the process tree with CPU and
+     memory, groups processes by the systemd unit, container, or pod they
+     run in (with their logs), and highlights zombies and runaway
+     processes (above 90% of a CPU or 25% of memory), so processes no
+     other agent manages become visible
+
+💻 Virtual Machines:
+   - "Virtual Machines" in the main menu lists libvirt domains with their
+     state, vCPUs, memory, and autostart setting (via virsh)
+   - View a guest's console log, and start, shut down, or reboot it
+   - Set "uri" in the "libvirt" section of the config file (for example
+     qemu:///system) to choose the libvirt connection
+
+🔌 Sessions:
+   - Port-forwards started in the background keep running while you browse
+   - List and stop them from "Port Forward Sessions" in the main menu;
+     they are stopped when the application exits
 
 NAVIGATION TIPS:
 --------------
@@ -44,14 +137,40 @@ NAVIGATION TIPS:
 
 COMMAND LINE USAGE:
 -----------------
-$ discover [OPTION]
+$ discover [OPTION]...
 
 Options:
   --help, -h          Display this help information
   --capture-state     Capture the current system state and exit
+  --failed            Print a report of failed systemd units (result, exit
+                      status, restarts, failure time, last journal lines)
+
+Kubernetes filters (glob patterns, may be repeated):
+  --context PATTERN            Only probe contexts matching PATTERN
+  --exclude-context PATTERN    Skip contexts matching PATTERN
+  --namespace PATTERN          Only probe namespaces matching PATTERN
+  --exclude-namespace PATTERN  Skip namespaces matching PATTERN
+  --active-context-only        Only probe the current context
+
+Filters can also be set in the "kubernetes" section of the config file
+(include_contexts, exclude_contexts, include_namespaces, exclude_namespaces,
+active_context_only); command line patterns are added to those.
+
+Systemd:
+  --all-users                  As root, include user services of all
+                               logged-in users (config: systemd.all_users)
+
+Services matching the "important_services" patterns in the "systemd"
+section of the config file have their full details captured into state.
 
 Running without arguments launches the interactive interface.
-The system state is saved to ~/.discover/discover_state.json
+Each capture of the system state is kept as a snapshot in
+~/.discover/snapshots, and the latest is copied to ~/.discover/discover_state.json
+The interactive interface also records host facts and search results in
+discover_state.json without taking a snapshot, so it may be newer than the
+latest snapshot. Set "max_snapshots" in the "state" section of the config
+file to delete the oldest snapshots beyond that count; all are kept by default.
+Settings are read from ~/.discover/config.json
 
 ================================================
 `)
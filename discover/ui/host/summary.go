@@ -0,0 +1,54 @@
+package hostUI
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/manifoldco/promptui"
+	"discover/agents/host"
+	"discover/models"
+	"discover/ui/utils"
+)
+
+// fullPercent is the space or inode usage above which a filesystem is highlighted
+const fullPercent = 90
+
+var fullStyle = promptui.Styler(promptui.FGRed, promptui.FGBold)
+
+// PrintHostSummary prints the host facts as a short summary screen
+func PrintHostSummary(facts models.HostFacts) {
+	fmt.Printf("🖥️  %s — %s, kernel %s (%s)\n", facts.Hostname, facts.OS, facts.Kernel, facts.Architecture)
+	fmt.Printf("   Up %s, load %.2f %.2f %.2f on %d CPU(s)\n", formatUptime(facts.Uptime),
+		facts.Load1, facts.Load5, facts.Load15, facts.CPUs)
+
+	memory := fmt.Sprintf("   Memory %s of %s used", utilsUI.FormatBytes(facts.MemoryTotal-facts.MemoryAvailable), utilsUI.FormatBytes(facts.MemoryTotal))
+	if facts.SwapTotal > 0 {
+		memory += fmt.Sprintf(", swap %s of %s used", utilsUI.FormatBytes(facts.SwapTotal-facts.SwapFree), utilsUI.FormatBytes(facts.SwapTotal))
+	}
+	fmt.Println(memory)
+
+	if len(facts.Filesystems) > 0 {
+		fmt.Printf("   %-24s %10s %10s %6s %7s\n", "Mount", "Size", "Free", "Use%", "Inode%")
+	}
+	for _, fs := range facts.Filesystems {
+		usage, inodes := host.UsagePercent(fs), host.InodePercent(fs)
+		line := fmt.Sprintf("   %-24s %10s %10s %5.0f%% %6.0f%%", fs.Mount, utilsUI.FormatBytes(fs.Size),
+			utilsUI.FormatBytes(fs.Available), usage, inodes)
+		if usage >= fullPercent || inodes >= fullPercent {
+			line = fullStyle(line)
+		}
+		fmt.Println(line)
+	}
+	fmt.Println()
+}
+
+// formatUptime prints an uptime in days, hours and minutes
+func formatUptime(uptime time.Duration) string {
+	days := int(uptime.Hours()) / 24
+	hours := int(uptime.Hours()) % 24
+	minutes := int(uptime.Minutes()) % 60
+	if days > 0 {
+		return fmt.Sprintf("%dd %dh %dm", days, hours, minutes)
+	}
+	return fmt.Sprintf("%dh %dm", hours, minutes)
+}
@@ -0,0 +1,81 @@
+package kernelUI
+
+import (
+	"fmt"
+
+	"github.com/manifoldco/promptui"
+	"discover/agents/cgroup"
+	"discover/agents/kernel"
+	"discover/models"
+)
+
+// eventIcons mark each kind of kernel event
+var eventIcons = map[string]string{
+	kernel.EventOOMKill:  "💀",
+	kernel.EventSegfault: "💥",
+	kernel.EventHungTask: "🐌",
+}
+
+// ShowKernelEventsMenu lists the OOM kills, segfaults and hung tasks of a boot and shows the
+// details of the selected event
+func ShowKernelEventsMenu() {
+	bootPrompt := promptui.Select{
+		Label: "🥾 Select a boot",
+		Items: []string{"🟢 Current Boot", "⏮️ Previous Boot"},
+	}
+	bootIndex, _, err := bootPrompt.Run()
+	if err != nil {
+		fmt.Printf("Boot selection failed: %v\n", err)
+		return
+	}
+
+	fmt.Println("Reading kernel messages...")
+	events, err := kernel.GetKernelEvents(-bootIndex)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	if len(events) == 0 {
+		fmt.Println("✅ No OOM kills, segfaults or hung tasks.")
+		return
+	}
+
+	// Newest first
+	options := []string{"⬅️ Back"}
+	for i := len(events) - 1; i >= 0; i-- {
+		event := events[i]
+		options = append(options, fmt.Sprintf("%s %s %s %s[%d] (%s)", eventIcons[event.Kind],
+			event.Timestamp.Format("2006-01-02 15:04:05"), event.Kind, event.Process, event.PID,
+			cgroup.Describe(event.Owner)))
+	}
+
+	for {
+		eventPrompt := promptui.Select{
+			Label: fmt.Sprintf("🐧 %d kernel event(s), select one for details", len(events)),
+			Items: options,
+			Size:  15,
+		}
+
+		index, result, err := eventPrompt.Run()
+		if err != nil {
+			fmt.Printf("Event selection failed: %v\n", err)
+			return
+		}
+		if result == "⬅️ Back" {
+			return
+		}
+		printEvent(events[len(events)-index])
+	}
+}
+
+// printEvent prints a kernel event with the resource the process belonged to
+func printEvent(event models.KernelEvent) {
+	fmt.Printf("Event:   %s\n", event.Kind)
+	fmt.Printf("Time:    %s\n", event.Timestamp.Format("2006-01-02 15:04:05"))
+	fmt.Printf("Process: %s (PID %d)\n", event.Process, event.PID)
+	fmt.Printf("Owner:   %s\n", cgroup.Describe(event.Owner))
+	if event.Owner.Cgroup != "" {
+		fmt.Printf("Cgroup:  %s\n", event.Owner.Cgroup)
+	}
+	fmt.Printf("Message: %s\n", event.Message)
+}
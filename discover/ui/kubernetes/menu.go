@@ -2,26 +2,90 @@ package kubernetesUI
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/manifoldco/promptui"
 	"discover/agents/kubernetes"
+	"discover/models"
+	"discover/ui/sessions"
+	"discover/ui/utils"
 )
 
+// nearLimitMark flags deployments with a container near its memory limit
+const nearLimitMark = " ⚠️"
+
 // ShowKubernetesMenu handles the Kubernetes context menu
 func ShowKubernetesMenu(contextName string) {
 	// Get all deployments in the selected context
-	deployments, err := kubernetes.GetKubernetesDeployments(contextName)
+	deployments, err := kubernetes.GetDeploymentsForAllNamespaces(contextName)
 	if err != nil {
 		fmt.Println(err)
 		return
 	}
 	
-	// Add back option
-	deploymentOptions := append([]string{"⬅️ Back"}, deployments...)
+	// Get Helm releases so deployments can be grouped under the release that owns them
+	releases, err := kubernetes.GetHelmReleases(contextName)
+	if err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+	
+	// Flag deployments with a container near its memory limit when metrics-server is installed
+	nearLimit := make(map[string]bool)
+	if kubernetes.MetricsAvailable(contextName) {
+		if err := kubernetes.AttachDeploymentUsage(contextName, deployments); err != nil {
+			fmt.Printf("Warning: %v\n", err)
+		}
+		for _, deployment := range deployments {
+			if deployment.NearMemoryLimit {
+				nearLimit[deployment.Namespace+"/"+deployment.Name] = true
+			}
+		}
+	}
+	
+	// Add back and node usage options, then releases, then deployments not managed by Helm
+	deploymentOptions := []string{"⬅️ Back", "🖥️ View Node Usage"}
+	releaseOptions := make(map[string]models.HelmRelease)
+	// Deployment names are only unique within a namespace
+	owned := make(map[string]bool)
+	for _, release := range releases {
+		option := fmt.Sprintf("📦 Helm: %s/%s (%s-%s, %s)", 
+			release.Namespace, release.Name, release.Chart, release.ChartVersion, release.Status)
+		for _, deployment := range release.Deployments {
+			owned[release.Namespace+"/"+deployment] = true
+			if nearLimit[release.Namespace+"/"+deployment] && !strings.HasSuffix(option, nearLimitMark) {
+				option += nearLimitMark
+			}
+		}
+		deploymentOptions = append(deploymentOptions, option)
+		releaseOptions[option] = release
+	}
+	deploymentNames := make(map[string]string)
+	for _, deployment := range deployments {
+		if !owned[deployment.Namespace+"/"+deployment.Name] {
+			option := deployment.Name
+			if nearLimit[deployment.Namespace+"/"+deployment.Name] {
+				option += nearLimitMark
+			}
+			deploymentOptions = append(deploymentOptions, option)
+			deploymentNames[option] = deployment.Name
+		}
+	}
+	
+	// Add the configured custom resources next to the deployments
+	customResources, err := kubernetes.GetCustomResources(contextName)
+	if err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+	resourceOptions := make(map[string]models.KubernetesCustomResource)
+	for _, resource := range customResources {
+		option := fmt.Sprintf("🧩 %s: %s/%s (%s)", resource.Kind.Kind, resource.Namespace, resource.Name, resource.Health)
+		deploymentOptions = append(deploymentOptions, option)
+		resourceOptions[option] = resource
+	}
 	
 	// Create a prompt for selecting a deployment
 	deploymentPrompt := promptui.Select{
-		Label: fmt.Sprintf("🔍 Select a deployment in context '%s' to view logs", contextName),
+		Label: fmt.Sprintf("🔍 Select a Helm release, deployment or resource in context '%s'", contextName),
 		Items: deploymentOptions,
 	}
 	
@@ -36,7 +100,260 @@ func ShowKubernetesMenu(contextName string) {
 		return
 	}
 	
-	// Get logs for the selected deployment
-	logs := kubernetes.GetKubernetesLogs(contextName, deploymentName)
-	fmt.Println(logs)
+	if deploymentName == "🖥️ View Node Usage" {
+		nodes, err := kubernetes.GetNodeUsage(contextName)
+		if err != nil {
+			fmt.Println(err)
+			fmt.Println("Node usage requires the metrics API (metrics-server) to be installed.")
+			return
+		}
+		printNodeUsage(nodes)
+		return
+	}
+	
+	if release, ok := releaseOptions[deploymentName]; ok {
+		showHelmReleaseMenu(contextName, release, nearLimit)
+		return
+	}
+	
+	if resource, ok := resourceOptions[deploymentName]; ok {
+		printCustomResource(resource)
+		return
+	}
+	
+	showDeploymentMenu(contextName, deploymentNames[deploymentName])
+}
+
+// showHelmReleaseMenu shows a Helm release and lets the user pick one of its deployments; those
+// in nearLimit (keyed by namespace/name) are flagged
+func showHelmReleaseMenu(contextName string, release models.HelmRelease, nearLimit map[string]bool) {
+	fmt.Printf("Release: %s\n", release.Name)
+	fmt.Printf("Namespace: %s\n", release.Namespace)
+	fmt.Printf("Chart: %s %s\n", release.Chart, release.ChartVersion)
+	fmt.Printf("App Version: %s\n", release.AppVersion)
+	fmt.Printf("Status: %s\n", release.Status)
+	fmt.Printf("Revision: %d\n", release.Revision)
+	fmt.Printf("Last Deployed: %s\n", release.LastDeployed.Format("2006-01-02 15:04:05"))
+	
+	if len(release.Deployments) == 0 {
+		fmt.Println("This release does not own any deployments.")
+		return
+	}
+	
+	options := []string{"⬅️ Back"}
+	for _, deployment := range release.Deployments {
+		option := deployment
+		if nearLimit[release.Namespace+"/"+deployment] {
+			option += nearLimitMark
+		}
+		options = append(options, option)
+	}
+	deploymentPrompt := promptui.Select{
+		Label: fmt.Sprintf("🔍 Select a deployment in release '%s'", release.Name),
+		Items: options,
+	}
+	
+	index, _, err := deploymentPrompt.Run()
+	if err != nil {
+		fmt.Printf("Deployment selection failed: %v\n", err)
+		return
+	}
+	
+	if index == 0 {
+		return
+	}
+	
+	showDeploymentMenu(contextName, release.Deployments[index-1])
+}
+
+// printCustomResource shows a custom resource's health and the conditions it was derived from
+func printCustomResource(resource models.KubernetesCustomResource) {
+	fmt.Printf("%s: %s\n", resource.Kind.Kind, resource.Name)
+	fmt.Printf("Namespace: %s\n", resource.Namespace)
+	fmt.Printf("API Version: %s/%s\n", resource.Kind.Group, resource.Kind.Version)
+	fmt.Printf("Health: %s\n", resource.Health)
+	
+	if len(resource.Conditions) == 0 {
+		fmt.Println("No status conditions reported.")
+		return
+	}
+	
+	fmt.Println("Conditions:")
+	for _, condition := range resource.Conditions {
+		fmt.Printf("  %s=%s", condition.Type, condition.Status)
+		if condition.Reason != "" {
+			fmt.Printf(" (%s)", condition.Reason)
+		}
+		if condition.Message != "" {
+			fmt.Printf(": %s", condition.Message)
+		}
+		fmt.Println()
+	}
+}
+
+// showDeploymentMenu handles the actions available for a single deployment
+func showDeploymentMenu(contextName, deploymentName string) {
+	actionPrompt := promptui.Select{
+		Label: fmt.Sprintf("🔍 Select an action for deployment '%s'", deploymentName),
+		Items: []string{
+			"📜 View Logs", 
+			"🌐 View Services & Ingresses", 
+			"📈 View Resource Usage", 
+			"🐚 Open Shell", 
+			"🔌 Port Forward", 
+			"⬅️ Back",
+		},
+	}
+	
+	_, actionSelection, err := actionPrompt.Run()
+	if err != nil {
+		fmt.Printf("Action selection failed: %v\n", err)
+		return
+	}
+	
+	switch actionSelection {
+	case "📜 View Logs":
+		// Get logs for the selected deployment
+		logs := kubernetes.GetKubernetesLogs(contextName, deploymentName)
+		fmt.Println(logs)
+		
+	case "🌐 View Services & Ingresses":
+		services, ingresses, err := kubernetes.GetDeploymentRoutes(contextName, deploymentName)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		printDeploymentRoutes(deploymentName, services, ingresses)
+		
+	case "📈 View Resource Usage":
+		usage, err := kubernetes.GetDeploymentUsage(contextName, deploymentName)
+		if err != nil {
+			fmt.Println(err)
+			fmt.Println("Resource usage requires the metrics API (metrics-server) to be installed.")
+			return
+		}
+		printContainerUsage(deploymentName, usage)
+		
+	case "🐚 Open Shell":
+		if err := kubernetes.OpenShell(contextName, deploymentName); err != nil {
+			fmt.Println(err)
+		}
+		
+	case "🔌 Port Forward":
+		localPort, remotePort, background, err := sessionsUI.PromptPortForward()
+		if err != nil {
+			fmt.Printf("Port-forward setup failed: %v\n", err)
+			return
+		}
+		if err := kubernetes.PortForward(contextName, deploymentName, localPort, remotePort, background); err != nil {
+			fmt.Println(err)
+		} else if background {
+			fmt.Printf("Forwarding localhost:%d to %s port %d in the background\n", localPort, deploymentName, remotePort)
+		}
+	}
+}
+
+// printContainerUsage shows each container's usage against its requests and limits,
+// warning about containers close to their memory limit
+func printContainerUsage(deploymentName string, usage []models.KubernetesContainerUsage) {
+	if len(usage) == 0 {
+		fmt.Printf("No usage metrics reported for deployment %s.\n", deploymentName)
+		return
+	}
+	
+	fmt.Printf("Resource usage for %s:\n", deploymentName)
+	for _, container := range usage {
+		fmt.Printf("  %s/%s\n", container.Pod, container.Container)
+		fmt.Printf("    CPU: %s (request %s, limit %s)\n", 
+			formatCPU(container.CPUUsage), formatCPU(container.CPURequest), formatCPU(container.CPULimit))
+		fmt.Printf("    Memory: %s (request %s, limit %s)\n", 
+			formatMemory(container.MemoryUsage), formatMemory(container.MemoryRequest), formatMemory(container.MemoryLimit))
+		if kubernetes.NearMemoryLimit(container) {
+			fmt.Printf("    ⚠️ Using %d%% of its memory limit, at risk of being OOMKilled\n", 
+				container.MemoryUsage*100/container.MemoryLimit)
+		}
+	}
+}
+
+// printNodeUsage shows each node's usage against its allocatable capacity
+func printNodeUsage(nodes []models.KubernetesNodeUsage) {
+	if len(nodes) == 0 {
+		fmt.Println("No node metrics reported.")
+		return
+	}
+	
+	for _, node := range nodes {
+		fmt.Printf("%s\n", node.Name)
+		fmt.Printf("  CPU: %s of %s%s\n", formatCPU(node.CPUUsage), formatCPU(node.CPUAllocatable), 
+			formatPercent(node.CPUUsage, node.CPUAllocatable))
+		fmt.Printf("  Memory: %s of %s%s\n", formatMemory(node.MemoryUsage), formatMemory(node.MemoryAllocatable), 
+			formatPercent(node.MemoryUsage, node.MemoryAllocatable))
+	}
+}
+
+// formatCPU renders millicores, or "none" when unset
+func formatCPU(millicores int64) string {
+	if millicores == 0 {
+		return "none"
+	}
+	return fmt.Sprintf("%dm", millicores)
+}
+
+// formatMemory renders bytes in binary units, or "none" when unset
+func formatMemory(bytes int64) string {
+	if bytes <= 0 {
+		return "none"
+	}
+	return utilsUI.FormatBytes(uint64(bytes))
+}
+
+// formatPercent renders usage as a percentage of capacity, or nothing when capacity is unknown
+func formatPercent(used, capacity int64) string {
+	if capacity == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (%d%%)", used*100/capacity)
+}
+
+// printDeploymentRoutes shows how traffic reaches a deployment and whether its endpoints are ready
+func printDeploymentRoutes(deploymentName string, services []models.KubernetesService, ingresses []models.KubernetesIngress) {
+	if len(services) == 0 {
+		fmt.Printf("No services select the pods of deployment %s; it is not reachable through a service.\n", deploymentName)
+		return
+	}
+	
+	fmt.Printf("Services routing to %s:\n", deploymentName)
+	for _, service := range services {
+		fmt.Printf("  %s (%s, %s)\n", service.Name, service.Type, service.ClusterIP)
+		for _, port := range service.Ports {
+			if port.NodePort != 0 {
+				fmt.Printf("    Port: %d/%s -> %s (node port %d)\n", port.Port, port.Protocol, port.TargetPort, port.NodePort)
+			} else {
+				fmt.Printf("    Port: %d/%s -> %s\n", port.Port, port.Protocol, port.TargetPort)
+			}
+		}
+		fmt.Printf("    Ready endpoints: %d, Not ready: %d\n", len(service.ReadyEndpoints), len(service.NotReadyEndpoints))
+		for _, endpoint := range service.ReadyEndpoints {
+			fmt.Printf("      ✅ %s\n", endpoint)
+		}
+		for _, endpoint := range service.NotReadyEndpoints {
+			fmt.Printf("      ❌ %s\n", endpoint)
+		}
+		if len(service.ReadyEndpoints) == 0 {
+			fmt.Println("    Warning: no ready endpoints, traffic to this service will fail")
+		}
+	}
+	
+	if len(ingresses) == 0 {
+		fmt.Println("No ingresses route to these services.")
+		return
+	}
+	
+	fmt.Println("Ingresses:")
+	for _, ingress := range ingresses {
+		fmt.Printf("  %s (class: %s)\n", ingress.Name, ingress.ClassName)
+		for _, rule := range ingress.Rules {
+			fmt.Printf("    %s%s -> %s:%s\n", rule.Host, rule.Path, rule.ServiceName, rule.ServicePort)
+		}
+	}
 }
\ No newline at end of file
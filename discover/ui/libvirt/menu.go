@@ -0,0 +1,134 @@
+package libvirtUI
+
+import (
+	"fmt"
+
+	"github.com/manifoldco/promptui"
+	"discover/agents/libvirt"
+	"discover/models"
+	"discover/ui/utils"
+)
+
+// machineActions maps the menu labels of the domain actions to the libvirt actions
+var machineActions = map[string]string{
+	"▶️ Start":    libvirt.ActionStart,
+	"⏹️ Shutdown": libvirt.ActionShutdown,
+	"🔄 Reboot":   libvirt.ActionReboot,
+}
+
+// ShowVirtualMachinesMenu lists the libvirt domains and opens the menu of the selected one
+func ShowVirtualMachinesMenu() {
+	for {
+		machines, err := libvirt.GetVirtualMachines()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		if len(machines) == 0 {
+			fmt.Println("No libvirt domains found.")
+			return
+		}
+
+		options := []string{"⬅️ Back"}
+		for _, machine := range machines {
+			options = append(options, machineLabel(machine))
+		}
+		machinePrompt := promptui.Select{
+			Label: "💻 Select a virtual machine",
+			Items: options,
+			Size:  15,
+		}
+		index, result, err := machinePrompt.Run()
+		if err != nil {
+			fmt.Printf("Virtual machine selection failed: %v\n", err)
+			return
+		}
+		if result == "⬅️ Back" {
+			return
+		}
+		showMachineMenu(machines[index-1])
+	}
+}
+
+// machineLabel describes a domain in one line
+func machineLabel(machine models.VirtualMachine) string {
+	icon := "⚪"
+	switch machine.State {
+	case "running":
+		icon = "🟢"
+	case "paused", "pmsuspended":
+		icon = "🟡"
+	case "crashed":
+		icon = "🔴"
+	}
+	label := fmt.Sprintf("%s %s (%s, %d vCPU, %s)", icon, machine.Name, machine.State, machine.VCPUs, utilsUI.FormatBytes(machine.MaxMemory))
+	if machine.Autostart {
+		label += " ⚡ autostart"
+	}
+	return label
+}
+
+// showMachineMenu shows the details, console log and actions of a domain
+func showMachineMenu(machine models.VirtualMachine) {
+	for {
+		prompt := promptui.Select{
+			Label: fmt.Sprintf("Select an action for %s (%s)", machine.Name, machine.State),
+			Items: []string{"📜 View Console Log", "🔍 View Details", "▶️ Start", "⏹️ Shutdown", "🔄 Reboot", "⬅️ Back"},
+		}
+		_, result, err := prompt.Run()
+		if err != nil {
+			fmt.Printf("Prompt failed %v\n", err)
+			return
+		}
+
+		switch result {
+		case "⬅️ Back":
+			return
+		case "📜 View Console Log":
+			fmt.Println(libvirt.GetConsoleLog(machine.Name))
+		case "🔍 View Details":
+			printMachine(machine)
+		default:
+			runMachineAction(machine, machineActions[result])
+			if refreshed, err := libvirt.GetVirtualMachine(machine.Name); err == nil {
+				machine = refreshed
+			}
+		}
+	}
+}
+
+// runMachineAction confirms an action on a domain and runs it
+func runMachineAction(machine models.VirtualMachine, action string) {
+	confirmPrompt := promptui.Prompt{
+		Label:     fmt.Sprintf("Run %s on virtual machine '%s'", action, machine.Name),
+		IsConfirm: true,
+	}
+	if _, err := confirmPrompt.Run(); err != nil {
+		fmt.Println("Cancelled.")
+		return
+	}
+
+	if err := libvirt.ControlVirtualMachine(machine.Name, action); err != nil {
+		fmt.Println(err)
+		return
+	}
+	if action == libvirt.ActionStart {
+		fmt.Printf("✅ %s started.\n", machine.Name)
+		return
+	}
+	fmt.Printf("✅ Sent %s to %s; the guest decides when it completes.\n", action, machine.Name)
+}
+
+// printMachine prints the details of a domain
+func printMachine(machine models.VirtualMachine) {
+	fmt.Printf("Name:        %s\n", machine.Name)
+	fmt.Printf("UUID:        %s\n", machine.UUID)
+	if machine.ID != "" {
+		fmt.Printf("ID:          %s\n", machine.ID)
+	}
+	fmt.Printf("State:       %s\n", machine.State)
+	fmt.Printf("vCPUs:       %d\n", machine.VCPUs)
+	fmt.Printf("Memory:      %s used of %s max\n", utilsUI.FormatBytes(machine.UsedMemory), utilsUI.FormatBytes(machine.MaxMemory))
+	fmt.Printf("Autostart:   %t\n", machine.Autostart)
+	fmt.Printf("Persistent:  %t\n", machine.Persistent)
+}
@@ -2,22 +2,50 @@ package ui
 
 import (
 	"fmt"
+	"os/exec"
 	"strings"
 
 	"github.com/manifoldco/promptui"
+	"discover/agents/cron"
 	"discover/agents/docker"
+	"discover/agents/host"
 	"discover/agents/kubernetes"
+	"discover/agents/pm2"
+	"discover/agents/supervisor"
 	"discover/agents/systemd"
+	"discover/config"
 	"discover/models"
+	"discover/sessions"
 	"discover/state"
+	"discover/ui/containerd"
+	"discover/ui/cron"
 	"discover/ui/docker"
+	"discover/ui/host"
+	"discover/ui/kernel"
 	"discover/ui/kubernetes"
+	"discover/ui/libvirt"
+	"discover/ui/pm2"
+	"discover/ui/ports"
+	"discover/ui/processes"
 	"discover/ui/systemd"
 	"discover/ui/help"
+	"discover/ui/supervisor"
+	"discover/ui/utils"
+	"discover/ui/sessions"
 )
 
 // StartMainMenu launches the main interactive menu
 func StartMainMenu() {
+	// Don't leave background port-forwards running after we exit
+	defer sessions.StopAll()
+	
+	// Show what machine we are on, and record it with the state
+	facts := host.GetHostFacts()
+	hostUI.PrintHostSummary(facts)
+	if err := state.SaveHostFacts(facts); err != nil {
+		fmt.Printf("Warning: Failed to save host facts: %v\n", err)
+	}
+	
 	// Loop through the main menu until user exits
 	for {
 		resourceTypes := []string{
@@ -25,7 +53,17 @@ func StartMainMenu() {
 			"🐳 Docker Only",
 			"☸️ Kubernetes Only",
 			"⚙️ Systemd Only",
+			"🕐 Cron Only",
+			"🧰 Supervisord & PM2 Only",
+			"🚨 Failed Units",
+			"⏱️ Boot Analysis",
+			"🐧 Kernel Events",
+			"🔊 Listening Ports",
+			"🧬 Processes",
+			"💻 Virtual Machines",
+			"🧱 Containerd & CRI",
 			"📊 Capture System State Only",
+			"🔌 Port Forward Sessions",
 			"❓ Help",
 			"❌ Exit Application",
 		}
@@ -47,10 +85,66 @@ func StartMainMenu() {
 			return
 		}
 		
+		// Handle sessions option
+		if typeResult == "🔌 Port Forward Sessions" {
+			sessionsUI.ShowSessionsMenu()
+			utilsUI.PauseForUser()
+			continue // Return to main menu
+		}
+		
+		// Handle failed units option
+		if typeResult == "🚨 Failed Units" {
+			systemdUI.ShowFailedUnitsMenu()
+			utilsUI.PauseForUser()
+			continue // Return to main menu
+		}
+		
+		// Handle boot analysis option
+		if typeResult == "⏱️ Boot Analysis" {
+			systemdUI.ShowBootAnalysis()
+			utilsUI.PauseForUser()
+			continue // Return to main menu
+		}
+		
+		// Handle kernel events option
+		if typeResult == "🐧 Kernel Events" {
+			kernelUI.ShowKernelEventsMenu()
+			utilsUI.PauseForUser()
+			continue // Return to main menu
+		}
+		
+		// Handle listening ports option
+		if typeResult == "🔊 Listening Ports" {
+			portsUI.ShowPortsMenu()
+			utilsUI.PauseForUser()
+			continue // Return to main menu
+		}
+		
+		// Handle processes option
+		if typeResult == "🧬 Processes" {
+			processesUI.ShowProcessMenu()
+			utilsUI.PauseForUser()
+			continue // Return to main menu
+		}
+		
+		// Handle virtual machines option
+		if typeResult == "💻 Virtual Machines" {
+			libvirtUI.ShowVirtualMachinesMenu()
+			utilsUI.PauseForUser()
+			continue // Return to main menu
+		}
+		
+		// Handle containerd option
+		if typeResult == "🧱 Containerd & CRI" {
+			containerdUI.ShowContainerdMenu()
+			utilsUI.PauseForUser()
+			continue // Return to main menu
+		}
+		
 		// Handle help option
 		if typeResult == "❓ Help" {
 			help.ShowHelpPage()
-			PauseForUser()
+			utilsUI.PauseForUser()
 			continue // Return to main menu
 		}
 		
@@ -59,7 +153,7 @@ func StartMainMenu() {
 			if err := CaptureSystemState(); err != nil {
 				fmt.Println(err)
 			}
-			PauseForUser()
+			utilsUI.PauseForUser()
 			continue // Return to main menu
 		}
 		
@@ -67,26 +161,38 @@ func StartMainMenu() {
 		var dockerProjects []models.DockerProject
 		var k8sConfigs []models.KubernetesConfig
 		var systemdServices []models.SystemdService
+		var cronJobs []models.CronJob
+		var programs []models.ManagedProgram
 		
 		// Fetch only the selected resource types
 		switch typeIndex {
 		case 0: // All Resource Types
 			fmt.Println("Searching for all resource types...")
+			showKubernetesFilters()
 			dockerProjects = docker.GetDockerComposeProjects()
 			k8sConfigs = kubernetes.GetKubernetesConfigs()
 			systemdServices = systemd.GetSystemdServices()
+			cronJobs = cron.GetCronJobs()
+			programs = getManagedPrograms()
 		case 1: // Docker Only
 			fmt.Println("Searching for Docker resources...")
 			dockerProjects = docker.GetDockerComposeProjects()
 		case 2: // Kubernetes Only
 			fmt.Println("Searching for Kubernetes resources...")
+			showKubernetesFilters()
 			k8sConfigs = kubernetes.GetKubernetesConfigs()
 		case 3: // Systemd Only
 			fmt.Println("Searching for Systemd resources...")
 			systemdServices = systemd.GetSystemdServices()
+		case 4: // Cron Only
+			fmt.Println("Searching for cron jobs...")
+			cronJobs = cron.GetCronJobs()
+		case 5: // Supervisord & PM2 Only
+			fmt.Println("Searching for supervisord programs and PM2 apps...")
+			programs = getManagedPrograms()
 		}
 		
-		// Capture the system state with what we've found
+		// Record what we've found in the state file; only a full capture takes a snapshot
 		if err := state.UpdateSystemState(
 			dockerProjects, 
 			k8sConfigs, 
@@ -95,15 +201,48 @@ func StartMainMenu() {
 			fmt.Printf("Warning: Failed to capture system state: %v\n", err)
 		}
 		
-		showResourceSelectionMenu(dockerProjects, k8sConfigs, systemdServices)
+		showResourceSelectionMenu(dockerProjects, k8sConfigs, systemdServices, cronJobs, programs)
+	}
+}
+
+// showKubernetesFilters tells the user which Kubernetes contexts and namespaces are being skipped
+func showKubernetesFilters() {
+	settings := config.Current().Kubernetes
+	if !settings.HasFilters() {
+		return
+	}
+	
+	fmt.Println("Kubernetes filters in effect:")
+	if settings.ActiveContextOnly {
+		fmt.Println("  Only the active context")
+	}
+	if len(settings.IncludeContexts) > 0 {
+		fmt.Printf("  Contexts: %s\n", strings.Join(settings.IncludeContexts, ", "))
+	}
+	if len(settings.ExcludeContexts) > 0 {
+		fmt.Printf("  Excluded contexts: %s\n", strings.Join(settings.ExcludeContexts, ", "))
+	}
+	if len(settings.IncludeNamespaces) > 0 {
+		fmt.Printf("  Namespaces: %s\n", strings.Join(settings.IncludeNamespaces, ", "))
+	}
+	if len(settings.ExcludeNamespaces) > 0 {
+		fmt.Printf("  Excluded namespaces: %s\n", strings.Join(settings.ExcludeNamespaces, ", "))
 	}
 }
 
+// unitBrowserOption opens the browser for systemd timers, sockets, mounts and targets
+const unitBrowserOption = "⏰ Systemd: Timers, Sockets, Mounts & Targets"
+
+// cronOption opens the cron jobs found
+const cronOption = "🕐 Cron: Scheduled Jobs"
+
 // showResourceSelectionMenu displays the menu for selecting specific resources
 func showResourceSelectionMenu(
 	dockerProjects []models.DockerProject,
 	k8sConfigs []models.KubernetesConfig,
 	systemdServices []models.SystemdService,
+	cronJobs []models.CronJob,
+	programs []models.ManagedProgram,
 ) {
 	for {
 		// Build the selection options based on what we've fetched
@@ -119,13 +258,38 @@ func showResourceSelectionMenu(
 			options = append(options, fmt.Sprintf("☸️ Kubernetes: %s", config.Name))
 		}
 		
+		// Other unit types are browsed from their own menu when systemd was searched
+		if len(systemdServices) > 0 {
+			options = append(options, unitBrowserOption)
+		}
+		
+		if len(cronJobs) > 0 {
+			options = append(options, cronOption)
+		}
+		
+		serviceOptions := make(map[string]models.SystemdService)
 		for _, service := range systemdServices {
 			// Only include active services to avoid cluttering the menu
 			if strings.HasPrefix(service.Status, "active") {
-				options = append(options, fmt.Sprintf("⚙️ Systemd: %s", service.Name))
+				option := fmt.Sprintf("⚙️ Systemd: %s", service.Name)
+				if service.Scope == systemd.ScopeUser {
+					option = fmt.Sprintf("⚙️ Systemd (user %s): %s", service.User, service.Name)
+				}
+				options = append(options, option)
+				serviceOptions[option] = service
 			}
 		}
 		
+		programOptions := make(map[string]models.ManagedProgram)
+		for _, program := range programs {
+			option := fmt.Sprintf("🧰 Supervisord: %s (%s)", program.Name, program.State)
+			if program.Manager == pm2.Manager {
+				option = fmt.Sprintf("🟩 PM2: %s (%s)", program.Name, program.State)
+			}
+			options = append(options, option)
+			programOptions[option] = program
+		}
+		
 		// Check if we have any options besides the back and help options
 		if len(options) <= 2 {
 			fmt.Println("No resources found for the selected type(s).")
@@ -152,7 +316,7 @@ func showResourceSelectionMenu(
 		// Handle help option
 		if result == "❓ Help" {
 			help.ShowHelpPage()
-			PauseForUser()
+			utilsUI.PauseForUser()
 			continue
 		}
 		
@@ -161,11 +325,44 @@ func showResourceSelectionMenu(
 			dockerUI.ShowDockerMenu(strings.TrimPrefix(result, "🐳 Docker: "))
 		} else if strings.HasPrefix(result, "☸️ Kubernetes: ") {
 			kubernetesUI.ShowKubernetesMenu(strings.TrimPrefix(result, "☸️ Kubernetes: "))
-		} else if strings.HasPrefix(result, "⚙️ Systemd: ") {
-			systemdUI.ShowSystemdMenu(strings.TrimPrefix(result, "⚙️ Systemd: "))
+		} else if result == cronOption {
+			cronUI.ShowCronMenu(cronJobs)
+			continue
+		} else if result == unitBrowserOption {
+			systemdUI.ShowUnitBrowser()
+			continue
+		} else if service, ok := serviceOptions[result]; ok {
+			systemdUI.ShowSystemdMenu(service)
+		} else if program, ok := programOptions[result]; ok {
+			if program.Manager == pm2.Manager {
+				pm2UI.ShowPM2Menu(program)
+			} else {
+				supervisorUI.ShowSupervisorMenu(program)
+			}
 		}
 		
 		// Pause after displaying content
-		PauseForUser()
+		utilsUI.PauseForUser()
 	}
-}
\ No newline at end of file
+}
+
+// getManagedPrograms lists the programs of supervisord and the current user's PM2 apps, skipping
+// managers that are not installed
+func getManagedPrograms() []models.ManagedProgram {
+	var programs []models.ManagedProgram
+	if _, err := exec.LookPath("supervisorctl"); err == nil {
+		found, err := supervisor.GetSupervisorPrograms()
+		if err != nil {
+			fmt.Printf("Warning: %v\n", err)
+		}
+		programs = append(programs, found...)
+	}
+	if _, err := exec.LookPath("pm2"); err == nil {
+		found, err := pm2.GetPM2Apps()
+		if err != nil {
+			fmt.Printf("Warning: %v\n", err)
+		}
+		programs = append(programs, found...)
+	}
+	return programs
+}
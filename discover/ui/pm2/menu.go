@@ -0,0 +1,79 @@
+package pm2UI
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/manifoldco/promptui"
+	"discover/agents/pm2"
+	"discover/models"
+	"discover/ui/utils"
+)
+
+// ShowPM2Menu handles the PM2 app menu
+func ShowPM2Menu(app models.ManagedProgram) {
+	// Create a prompt for app actions
+	actionPrompt := promptui.Select{
+		Label: fmt.Sprintf("🔍 Select an action for PM2 app '%s'", app.Name),
+		Items: []string{"📜 View Logs", "⚠️ View Error Logs", "📊 View Details", "🔄 Restart App", "⬅️ Back"},
+	}
+	
+	_, actionSelection, err := actionPrompt.Run()
+	if err != nil {
+		fmt.Printf("Action selection failed: %v\n", err)
+		return
+	}
+	
+	switch actionSelection {
+	case "⬅️ Back":
+		return
+		
+	case "📜 View Logs":
+		fmt.Println(pm2.GetAppLogs(app, false))
+		
+	case "⚠️ View Error Logs":
+		fmt.Println(pm2.GetAppLogs(app, true))
+		
+	case "📊 View Details":
+		fmt.Printf("App: %s (id %s)\n", app.Name, app.ID)
+		fmt.Printf("Status: %s\n", app.State)
+		if app.PID != 0 {
+			fmt.Printf("PID: %d\n", app.PID)
+			fmt.Printf("Uptime: %s\n", app.Uptime)
+		}
+		fmt.Printf("Restarts: %d\n", app.Restarts)
+		fmt.Printf("CPU: %.1f%%\n", app.CPUPercent)
+		fmt.Printf("Memory: %s\n", utilsUI.FormatBytes(app.MemoryBytes))
+		fmt.Printf("Output Log: %s\n", app.OutLog)
+		fmt.Printf("Error Log: %s\n", app.ErrLog)
+		
+	case "🔄 Restart App":
+		confirmPrompt := promptui.Prompt{
+			Label:     fmt.Sprintf("Restart PM2 app '%s'", app.Name),
+			IsConfirm: true,
+		}
+		if _, err := confirmPrompt.Run(); err != nil {
+			fmt.Println("Cancelled.")
+			return
+		}
+		
+		fmt.Printf("Restarting %s...\n", app.Name)
+		if err := pm2.RestartApp(app); err != nil {
+			fmt.Println(err)
+			return
+		}
+		
+		// Report the state the app settled in
+		time.Sleep(time.Second)
+		apps, err := pm2.GetPM2Apps()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		for _, restarted := range apps {
+			if restarted.ID == app.ID {
+				fmt.Printf("✅ %s is %s (restarts: %d)\n", restarted.Name, restarted.State, restarted.Restarts)
+			}
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package portsUI
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/manifoldco/promptui"
+	"discover/agents/cgroup"
+	"discover/agents/ports"
+	"discover/models"
+)
+
+// ShowPortsMenu lists the listening TCP ports and what owns them, and opens the logs of the
+// unit, container or pod behind the selected port
+func ShowPortsMenu() {
+	sockets, err := ports.GetListeningPorts()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	if len(sockets) == 0 {
+		fmt.Println("No listening TCP ports found.")
+		return
+	}
+
+	shown := sockets
+	for {
+		options := []string{"⬅️ Back", "🔎 Find Port..."}
+		for _, socket := range shown {
+			options = append(options, socketLabel(socket))
+		}
+
+		portPrompt := promptui.Select{
+			Label: fmt.Sprintf("🔌 %d listening port(s), select one for its owner and logs", len(shown)),
+			Items: options,
+			Size:  15,
+		}
+		index, result, err := portPrompt.Run()
+		if err != nil {
+			fmt.Printf("Port selection failed: %v\n", err)
+			return
+		}
+
+		switch result {
+		case "⬅️ Back":
+			return
+		case "🔎 Find Port...":
+			port, ok := askPort()
+			if !ok {
+				continue
+			}
+			matching := ports.FindPort(sockets, port)
+			if len(matching) == 0 {
+				fmt.Printf("Nothing is listening on port %d.\n", port)
+				shown = sockets
+				continue
+			}
+			shown = matching
+		default:
+			showSocket(shown[index-2])
+		}
+	}
+}
+
+// askPort asks for a port number
+func askPort() (int, bool) {
+	portPrompt := promptui.Prompt{
+		Label: "Port",
+		Validate: func(input string) error {
+			port, err := strconv.Atoi(input)
+			if err != nil || port < 1 || port > 65535 {
+				return fmt.Errorf("enter a port between 1 and 65535")
+			}
+			return nil
+		},
+	}
+	input, err := portPrompt.Run()
+	if err != nil {
+		return 0, false
+	}
+	port, _ := strconv.Atoi(input)
+	return port, true
+}
+
+// socketLabel describes a listening socket in one line
+func socketLabel(socket models.ListeningSocket) string {
+	process := "unknown process"
+	if socket.PID != 0 {
+		process = fmt.Sprintf("%s[%d]", socket.Process, socket.PID)
+	}
+	label := fmt.Sprintf("%-5s %s  %s (%s)", socket.Protocol, hostPort(socket), process, socket.User)
+	if socket.OtherNamespace {
+		label += " [own netns]"
+	}
+	if socket.Owner.Kind != "" {
+		label += " → " + cgroup.Describe(socket.Owner)
+	}
+	return label
+}
+
+// hostPort joins the address and port of a socket, bracketing IPv6 addresses
+func hostPort(socket models.ListeningSocket) string {
+	return net.JoinHostPort(socket.Address, strconv.Itoa(socket.Port))
+}
+
+// showSocket prints the details of a socket and offers to show the logs of its owner
+func showSocket(socket models.ListeningSocket) {
+	fmt.Printf("Address: %s/%s\n", hostPort(socket), socket.Protocol)
+	fmt.Printf("User:    %s\n", socket.User)
+	if socket.OtherNamespace {
+		fmt.Println("Network: own network namespace, not reachable on the host's addresses")
+	}
+	if socket.PID == 0 {
+		fmt.Println("Process: unknown (run as root to see processes of other users)")
+		return
+	}
+	fmt.Printf("Process: %s (PID %d)\n", socket.Process, socket.PID)
+	fmt.Printf("Owner:   %s\n", cgroup.Describe(socket.Owner))
+	if socket.Owner.Kind == "" {
+		return
+	}
+
+	logsOption := "📜 View Logs of " + cgroup.Describe(socket.Owner)
+	actionPrompt := promptui.Select{
+		Label: "Select an action",
+		Items: []string{logsOption, "⬅️ Back"},
+	}
+	_, result, err := actionPrompt.Run()
+	if err != nil || result != logsOption {
+		return
+	}
+	fmt.Println(cgroup.Logs(socket.Owner))
+}
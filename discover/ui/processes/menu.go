@@ -0,0 +1,199 @@
+package processesUI
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/manifoldco/promptui"
+	"discover/agents/cgroup"
+	"discover/agents/processes"
+	"discover/models"
+	"discover/ui/utils"
+)
+
+var (
+	runawayStyle = promptui.Styler(promptui.FGRed, promptui.FGBold)
+	zombieStyle  = promptui.Styler(promptui.FGYellow)
+)
+
+// ShowProcessMenu shows the process tree, processes grouped by the unit, container or pod they
+// belong to, and the zombie and runaway processes
+func ShowProcessMenu() {
+	fmt.Printf("Sampling processes for %s...\n", processes.SampleInterval)
+	list, err := processes.GetProcesses()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	for {
+		menuPrompt := promptui.Select{
+			Label: fmt.Sprintf("🧬 %d processes", len(list)),
+			Items: []string{"⬅️ Back", "🌳 Process Tree", "📦 Group by Unit, Container or Pod", "⚠️ Zombie & Runaway Processes", "🔄 Refresh"},
+		}
+		_, result, err := menuPrompt.Run()
+		if err != nil {
+			fmt.Printf("Prompt failed %v\n", err)
+			return
+		}
+
+		switch result {
+		case "⬅️ Back":
+			return
+		case "🌳 Process Tree":
+			printTree(list)
+		case "📦 Group by Unit, Container or Pod":
+			showGroups(processes.GroupByOwner(list))
+		case "⚠️ Zombie & Runaway Processes":
+			printProblems(list)
+		case "🔄 Refresh":
+			if refreshed, err := processes.GetProcesses(); err != nil {
+				fmt.Println(err)
+			} else {
+				list = refreshed
+			}
+		}
+	}
+}
+
+// printTree prints processes as a tree under their parents, marking where each subtree moves
+// into a different unit, container or pod
+func printTree(list []models.Process) {
+	byPID := make(map[int]models.Process)
+	for _, process := range list {
+		byPID[process.PID] = process
+	}
+	children := processes.Children(list)
+
+	var printNode func(pid int, prefix string, last bool, parentOwner string)
+	printNode = func(pid int, prefix string, last bool, parentOwner string) {
+		process := byPID[pid]
+		branch, childPrefix := "├─ ", prefix+"│  "
+		if last {
+			branch, childPrefix = "└─ ", prefix+"   "
+		}
+
+		line := processLine(process)
+		if owner := cgroup.Describe(process.Owner); owner != parentOwner {
+			line += "  [" + owner + "]"
+		}
+		fmt.Println(prefix + branch + styleProcess(process)(line))
+
+		for i, child := range children[pid] {
+			printNode(child, childPrefix, i == len(children[pid])-1, cgroup.Describe(process.Owner))
+		}
+	}
+
+	// Roots are processes whose parent is not listed, normally just init
+	var roots []int
+	for _, process := range list {
+		if _, ok := byPID[process.PPID]; !ok {
+			roots = append(roots, process.PID)
+		}
+	}
+	for i, root := range roots {
+		printNode(root, "", i == len(roots)-1, "")
+	}
+}
+
+// showGroups lists the process groups and shows the processes and logs of the selected one
+func showGroups(groups []models.ProcessGroup) {
+	options := []string{"⬅️ Back"}
+	for _, group := range groups {
+		options = append(options, fmt.Sprintf("%s: %d process(es), %.1f%% CPU, %s", cgroup.Describe(group.Owner),
+			len(group.Processes), group.CPUPercent, utilsUI.FormatBytes(group.MemoryBytes)))
+	}
+
+	for {
+		groupPrompt := promptui.Select{
+			Label: "📦 Select a unit, container or pod",
+			Items: options,
+			Size:  15,
+		}
+		index, result, err := groupPrompt.Run()
+		if err != nil {
+			fmt.Printf("Group selection failed: %v\n", err)
+			return
+		}
+		if result == "⬅️ Back" {
+			return
+		}
+		showGroup(groups[index-1])
+	}
+}
+
+// showGroup prints the processes of a group and offers the logs of its owner
+func showGroup(group models.ProcessGroup) {
+	fmt.Println(cgroup.Describe(group.Owner))
+	if group.Owner.Cgroup != "" {
+		fmt.Printf("Cgroup: %s\n", group.Owner.Cgroup)
+	}
+	for _, process := range group.Processes {
+		fmt.Println("  " + styleProcess(process)(processLine(process)))
+	}
+
+	if group.Owner.Kind == "" {
+		return
+	}
+	logsOption := "📜 View Logs"
+	actionPrompt := promptui.Select{
+		Label: "Select an action",
+		Items: []string{logsOption, "⬅️ Back"},
+	}
+	_, result, err := actionPrompt.Run()
+	if err != nil || result != logsOption {
+		return
+	}
+	fmt.Println(cgroup.Logs(group.Owner))
+}
+
+// printProblems lists zombie processes with the parent that should reap them, and runaway processes
+func printProblems(list []models.Process) {
+	byPID := make(map[int]models.Process)
+	for _, process := range list {
+		byPID[process.PID] = process
+	}
+
+	found := false
+	for _, process := range list {
+		switch {
+		case process.Zombie:
+			parent := byPID[process.PPID]
+			fmt.Println(zombieStyle(fmt.Sprintf("🧟 Zombie %s, not reaped by %s[%d] (%s)", processLine(process),
+				parent.Name, parent.PID, cgroup.Describe(parent.Owner))))
+			found = true
+		case process.Runaway:
+			fmt.Println(runawayStyle(fmt.Sprintf("🔥 Runaway %s (%s)", processLine(process), cgroup.Describe(process.Owner))))
+			found = true
+		}
+	}
+	if !found {
+		fmt.Printf("✅ No zombies, and no process above %d%% CPU or %d%% of memory.\n",
+			processes.RunawayCPUPercent, processes.RunawayMemoryPercent)
+	}
+}
+
+// processLine describes a process in one line
+func processLine(process models.Process) string {
+	command := process.Command
+	if command == "" {
+		command = "[" + process.Name + "]"
+	}
+	if len(command) > 80 {
+		command = command[:77] + "..."
+	}
+	return fmt.Sprintf("%d %s %s %.1f%% %s %s", process.PID, process.User, process.State,
+		process.CPUPercent, utilsUI.FormatBytes(process.MemoryBytes), strings.TrimSpace(command))
+}
+
+// styleProcess highlights runaway and zombie processes
+func styleProcess(process models.Process) func(interface{}) string {
+	switch {
+	case process.Runaway:
+		return runawayStyle
+	case process.Zombie:
+		return zombieStyle
+	default:
+		return func(text interface{}) string { return fmt.Sprint(text) }
+	}
+}
@@ -0,0 +1,93 @@
+package sessionsUI
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/manifoldco/promptui"
+	"discover/sessions"
+)
+
+// PromptPortForward asks for the local and remote ports and whether to keep the forward running in the background
+func PromptPortForward() (int, int, bool, error) {
+	validatePort := func(input string) error {
+		port, err := strconv.Atoi(input)
+		if err != nil || port < 1 || port > 65535 {
+			return fmt.Errorf("enter a port between 1 and 65535")
+		}
+		return nil
+	}
+	
+	remotePrompt := promptui.Prompt{
+		Label:    "Remote port",
+		Validate: validatePort,
+	}
+	remoteInput, err := remotePrompt.Run()
+	if err != nil {
+		return 0, 0, false, err
+	}
+	
+	localPrompt := promptui.Prompt{
+		Label:    "Local port",
+		Default:  remoteInput,
+		Validate: validatePort,
+	}
+	localInput, err := localPrompt.Run()
+	if err != nil {
+		return 0, 0, false, err
+	}
+	
+	modePrompt := promptui.Select{
+		Label: "Run the port-forward",
+		Items: []string{"In the foreground (Ctrl+C to stop)", "In the background (manage from Sessions)"},
+	}
+	modeIndex, _, err := modePrompt.Run()
+	if err != nil {
+		return 0, 0, false, err
+	}
+	
+	localPort, _ := strconv.Atoi(localInput)
+	remotePort, _ := strconv.Atoi(remoteInput)
+	return localPort, remotePort, modeIndex == 1, nil
+}
+
+// ShowSessionsMenu lists the background port-forwards and lets the user stop them
+func ShowSessionsMenu() {
+	for {
+		running := sessions.List()
+		if len(running) == 0 {
+			fmt.Println("No background sessions are running.")
+			return
+		}
+		
+		options := []string{"⬅️ Back"}
+		for _, session := range running {
+			options = append(options, fmt.Sprintf("🔌 #%d %s %s localhost:%d -> %d (running %s)", 
+				session.ID, session.Kind, session.Target, session.LocalPort, session.RemotePort, 
+				time.Since(session.Started).Round(time.Second)))
+		}
+		
+		sessionPrompt := promptui.Select{
+			Label: "🔍 Select a session to stop",
+			Items: options,
+		}
+		
+		index, result, err := sessionPrompt.Run()
+		if err != nil {
+			fmt.Printf("Session selection failed: %v\n", err)
+			return
+		}
+		
+		if result == "⬅️ Back" {
+			return
+		}
+		
+		session := running[index-1]
+		if err := sessions.Stop(session.ID); err != nil {
+			fmt.Println(err)
+		} else {
+			fmt.Printf("Stopped session #%d (%s)\n", session.ID, session.Target)
+		}
+	}
+}
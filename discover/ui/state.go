@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"discover/agents/docker"
+	"discover/agents/host"
 	"discover/agents/kubernetes"
 	"discover/agents/systemd"
 	"discover/state"
@@ -18,12 +19,29 @@ func CaptureSystemState() error {
 	k8sConfigs := kubernetes.GetKubernetesConfigs()
 	systemdServices := systemd.GetSystemdServices()
 	
-	// Update system state
-	err := state.UpdateSystemState(dockerProjects, k8sConfigs, systemdServices)
+	// Record everything in a single snapshot
+	snapshot, err := state.LoadState()
 	if err != nil {
 		return fmt.Errorf("error updating system state: %v", err)
 	}
+	snapshot.DockerProjects = dockerProjects
+	snapshot.KubernetesConfigs = k8sConfigs
+	snapshot.SystemdServices = systemdServices
 	
-	fmt.Printf("System state captured and saved to %s\n", state.GetStateFilePath())
+	// Record what machine this state describes
+	facts := host.GetHostFacts()
+	snapshot.Host = &facts
+	
+	// Record this boot's performance; systemd-analyze only reports it once booting has finished
+	if analysis, err := systemd.GetBootAnalysis(); err == nil {
+		state.AddBootAnalysis(&snapshot, analysis)
+	}
+	
+	snapshotID, err := state.SaveSnapshot(snapshot)
+	if err != nil {
+		return fmt.Errorf("error updating system state: %v", err)
+	}
+	
+	fmt.Printf("System state captured as snapshot %s in %s\n", snapshotID, state.GetSnapshotDir())
 	return nil
 }
\ No newline at end of file
@@ -0,0 +1,77 @@
+package supervisorUI
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/manifoldco/promptui"
+	"discover/agents/supervisor"
+	"discover/models"
+)
+
+// ShowSupervisorMenu handles the supervisord program menu
+func ShowSupervisorMenu(program models.ManagedProgram) {
+	// Create a prompt for program actions
+	actionPrompt := promptui.Select{
+		Label: fmt.Sprintf("🔍 Select an action for supervisord program '%s'", program.Name),
+		Items: []string{"📜 View Logs", "⚠️ View Error Logs", "📊 View Details", "🔄 Restart Program", "⬅️ Back"},
+	}
+	
+	_, actionSelection, err := actionPrompt.Run()
+	if err != nil {
+		fmt.Printf("Action selection failed: %v\n", err)
+		return
+	}
+	
+	switch actionSelection {
+	case "⬅️ Back":
+		return
+		
+	case "📜 View Logs":
+		fmt.Println(supervisor.GetProgramLogs(program.Name, false))
+		
+	case "⚠️ View Error Logs":
+		fmt.Println(supervisor.GetProgramLogs(program.Name, true))
+		
+	case "📊 View Details":
+		fmt.Printf("Program: %s\n", program.Name)
+		fmt.Printf("State: %s\n", program.State)
+		if program.PID != 0 {
+			fmt.Printf("PID: %d\n", program.PID)
+			fmt.Printf("Uptime: %s\n", program.Uptime)
+		}
+		if program.Description != "" {
+			fmt.Printf("Status: %s\n", program.Description)
+		}
+		fmt.Printf("Restarts (recent supervisord log): %d\n", program.Restarts)
+		
+	case "🔄 Restart Program":
+		confirmPrompt := promptui.Prompt{
+			Label:     fmt.Sprintf("Restart supervisord program '%s'", program.Name),
+			IsConfirm: true,
+		}
+		if _, err := confirmPrompt.Run(); err != nil {
+			fmt.Println("Cancelled.")
+			return
+		}
+		
+		fmt.Printf("Restarting %s...\n", program.Name)
+		if err := supervisor.RestartProgram(program.Name); err != nil {
+			fmt.Println(err)
+			return
+		}
+		
+		// Report the state the program settled in
+		time.Sleep(time.Second)
+		programs, err := supervisor.GetSupervisorPrograms()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		for _, restarted := range programs {
+			if restarted.Name == program.Name {
+				fmt.Printf("✅ %s is %s\n", restarted.Name, restarted.State)
+			}
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package systemdUI
+
+import (
+	"fmt"
+	"time"
+
+	"discover/agents/systemd"
+	"discover/models"
+	"discover/state"
+)
+
+// bootListLimit is how many units are shown in each boot analysis listing
+const bootListLimit = 10
+
+// ShowBootAnalysis captures the analysis of the current boot, stores it in state and compares
+// it with the previously captured boot
+func ShowBootAnalysis() {
+	fmt.Println("Analyzing the current boot...")
+	analysis, err := systemd.GetBootAnalysis()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	// Look up the previous boot before this one is saved
+	previous, hasPrevious, err := state.PreviousBootAnalysis(analysis.BootID)
+	if err != nil {
+		fmt.Printf("Warning: Failed to load previous boots: %v\n", err)
+	}
+	if err := state.SaveBootAnalysis(analysis); err != nil {
+		fmt.Printf("Warning: Failed to save the boot analysis: %v\n", err)
+	}
+
+	fmt.Printf("Boot: %s\n", analysis.BootID)
+	fmt.Printf("Total: %s", analysis.Total)
+	if hasPrevious {
+		fmt.Printf(" (%s than boot %s)", formatDelta(analysis.Total-previous.Total), shortBootID(previous.BootID))
+	}
+	fmt.Println()
+	for _, phase := range []struct {
+		name     string
+		duration time.Duration
+	}{
+		{"Firmware", analysis.Firmware},
+		{"Loader", analysis.Loader},
+		{"Kernel", analysis.Kernel},
+		{"Initrd", analysis.Initrd},
+		{"Userspace", analysis.Userspace},
+	} {
+		if phase.duration > 0 {
+			fmt.Printf("  %-10s %s\n", phase.name+":", phase.duration)
+		}
+	}
+
+	fmt.Println("\nSlowest units:")
+	for i, timing := range analysis.Blame {
+		if i == bootListLimit {
+			break
+		}
+		fmt.Printf("  %10s  %s\n", timing.Time.Round(time.Millisecond), timing.Unit)
+	}
+
+	fmt.Println("\nCritical chain:")
+	for _, unit := range analysis.CriticalChain {
+		line := fmt.Sprintf("  %s @%s", unit.Unit, unit.ActiveAt.Round(time.Millisecond))
+		if unit.Took > 0 {
+			line += fmt.Sprintf(" +%s", unit.Took.Round(time.Millisecond))
+		}
+		fmt.Println(line)
+	}
+
+	if !hasPrevious {
+		fmt.Println("\nNo earlier boot captured yet; the next boot will be compared with this one.")
+		return
+	}
+	printSlowerUnits(previous, analysis)
+}
+
+// printSlowerUnits lists the units that took longer to activate than in the previous boot
+func printSlowerUnits(previous, current models.BootAnalysis) {
+	fmt.Printf("\nSlower than boot %s (captured %s):\n", shortBootID(previous.BootID),
+		previous.Captured.Format("2006-01-02 15:04"))
+
+	shown := 0
+	for _, change := range systemd.CompareBoots(previous, current) {
+		if change.Current <= change.Previous || shown == bootListLimit {
+			break
+		}
+		fmt.Printf("  %-40s %10s -> %-10s (%s)\n", change.Unit, change.Previous.Round(time.Millisecond),
+			change.Current.Round(time.Millisecond), formatDelta(change.Current-change.Previous))
+		shown++
+	}
+	if shown == 0 {
+		fmt.Println("  No unit got slower.")
+	}
+}
+
+// formatDelta prints a duration difference as slower or faster
+func formatDelta(delta time.Duration) string {
+	if delta >= 0 {
+		return fmt.Sprintf("%s slower", delta.Round(time.Millisecond))
+	}
+	return fmt.Sprintf("%s faster", (-delta).Round(time.Millisecond))
+}
+
+// shortBootID shortens a boot ID for display
+func shortBootID(bootID string) string {
+	if len(bootID) > 8 {
+		return bootID[:8]
+	}
+	return bootID
+}
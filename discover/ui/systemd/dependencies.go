@@ -0,0 +1,76 @@
+package systemdUI
+
+import (
+	"fmt"
+
+	"github.com/manifoldco/promptui"
+	"discover/agents/systemd"
+	"discover/models"
+)
+
+// showDependencies prints what a service depends on and what depends on it, and offers to
+// export that part of the graph for incident docs
+func showDependencies(service models.SystemdService) {
+	unitName := service.Name + ".service"
+
+	fmt.Println("Building the unit dependency graph...")
+	graph, err := systemd.GetDependencyGraph(service)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	dependsOn, dependedBy := systemd.UnitDependencies(graph, unitName)
+	fmt.Printf("%s %s\n", stateMarker(graph.States[unitName]), unitName)
+
+	fmt.Println("Depends on:")
+	if len(dependsOn) == 0 {
+		fmt.Println("  (nothing)")
+	}
+	for _, edge := range dependsOn {
+		fmt.Printf("  %s %-8s %s (%s)\n", stateMarker(graph.States[edge.To]), edge.Type, edge.To, graph.States[edge.To])
+	}
+
+	fmt.Println("Depended on by:")
+	if len(dependedBy) == 0 {
+		fmt.Println("  (nothing)")
+	}
+	for _, edge := range dependedBy {
+		fmt.Printf("  %s %-8s %s (%s)\n", stateMarker(graph.States[edge.From]), edge.Type, edge.From, graph.States[edge.From])
+	}
+
+	subgraph := systemd.UnitSubgraph(graph, unitName)
+	for {
+		exportPrompt := promptui.Select{
+			Label: "📤 Export the dependencies",
+			Items: []string{"📄 Export as DOT", "🧜 Export as Mermaid", "⬅️ Back"},
+		}
+
+		_, selection, err := exportPrompt.Run()
+		if err != nil {
+			fmt.Printf("Export selection failed: %v\n", err)
+			return
+		}
+
+		switch selection {
+		case "📄 Export as DOT":
+			fmt.Println(systemd.GraphDOT(subgraph))
+		case "🧜 Export as Mermaid":
+			fmt.Println(systemd.GraphMermaid(subgraph))
+		default:
+			return
+		}
+	}
+}
+
+// stateMarker highlights failed units in dependency listings
+func stateMarker(state string) string {
+	switch state {
+	case "failed":
+		return "❌"
+	case "active":
+		return "✅"
+	default:
+		return "⚪"
+	}
+}
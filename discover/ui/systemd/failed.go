@@ -0,0 +1,99 @@
+package systemdUI
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/manifoldco/promptui"
+	"discover/agents/systemd"
+	"discover/models"
+	"discover/ui/utils"
+)
+
+// ShowFailedUnitsMenu lists the failed units and shows the triage details of the selected one
+func ShowFailedUnitsMenu() {
+	fmt.Println("Searching for failed systemd units...")
+	failed := systemd.GetFailedUnits()
+	if len(failed) == 0 {
+		fmt.Println("✅ No failed units.")
+		return
+	}
+
+	options := []string{"⬅️ Back", "📋 Print Full Report"}
+	for _, unit := range failed {
+		options = append(options, fmt.Sprintf("🚨 %s (%s, failed %s)",
+			unitLabel(unit.Name, unit.Scope, unit.User), failureReason(unit), utilsUI.FormatTime(unit.FailedAt)))
+	}
+
+	for {
+		unitPrompt := promptui.Select{
+			Label: fmt.Sprintf("🔍 %d failed unit(s), select one to triage", len(failed)),
+			Items: options,
+			Size:  15,
+		}
+
+		index, result, err := unitPrompt.Run()
+		if err != nil {
+			fmt.Printf("Unit selection failed: %v\n", err)
+			return
+		}
+
+		switch result {
+		case "⬅️ Back":
+			return
+		case "📋 Print Full Report":
+			PrintFailedUnitsReport(failed)
+		default:
+			printFailedUnit(failed[index-2])
+		}
+	}
+}
+
+// PrintFailedUnitsReport prints the triage details of every failed unit, for handing off on-call
+func PrintFailedUnitsReport(failed []models.SystemdFailedUnit) {
+	fmt.Printf("Failed units report (%s)\n", time.Now().Format("2006-01-02 15:04:05 MST"))
+	if len(failed) == 0 {
+		fmt.Println("No failed units.")
+		return
+	}
+
+	fmt.Printf("%d failed unit(s)\n", len(failed))
+	for _, unit := range failed {
+		fmt.Println(strings.Repeat("=", 60))
+		printFailedUnit(unit)
+	}
+}
+
+// failureReason summarizes the result and exit status of a failed unit
+func failureReason(unit models.SystemdFailedUnit) string {
+	reason := unit.Result
+	if reason == "" {
+		reason = "unknown result"
+	}
+	if unit.ExitStatus != "" {
+		reason += ", " + unit.ExitStatus
+	}
+	return reason
+}
+
+// printFailedUnit prints the triage details of a failed unit
+func printFailedUnit(unit models.SystemdFailedUnit) {
+	fmt.Printf("Unit: %s\n", unit.Name)
+	printScope(unit.Scope, unit.User)
+	fmt.Printf("Description: %s\n", unit.Description)
+	fmt.Printf("Result: %s\n", unit.Result)
+	if unit.ExitStatus != "" {
+		fmt.Printf("Exit Status: %s\n", unit.ExitStatus)
+	}
+	fmt.Printf("Restarts: %d\n", unit.NRestarts)
+	fmt.Printf("Failed At: %s\n", utilsUI.FormatTime(unit.FailedAt))
+
+	fmt.Printf("Last %d journal lines:\n", systemd.FailedJournalLines)
+	if len(unit.Journal) == 0 {
+		fmt.Println("  (no journal entries)")
+	}
+	for _, line := range unit.Journal {
+		fmt.Printf("  %s\n", line)
+	}
+}
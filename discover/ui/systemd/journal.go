@@ -0,0 +1,153 @@
+package systemdUI
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/manifoldco/promptui"
+	"discover/agents/systemd"
+	"discover/models"
+)
+
+// priorityFilters are the priority filter options, from least to most strict, with the
+// journalctl priority each one keeps
+var priorityFilters = []struct {
+	Label    string
+	Priority string
+}{
+	{"📋 All Priorities", ""},
+	{"ℹ️ Info and above", "info"},
+	{"🔵 Notice and above", "notice"},
+	{"🟡 Warnings and above", "warning"},
+	{"🔴 Errors and above", "err"},
+	{"🚨 Critical and above", "crit"},
+}
+
+// showJournal asks for a boot and a priority filter, then prints the matching journal entries
+// of a service colored by priority
+func showJournal(service models.SystemdService) {
+	query := systemd.JournalQuery{Fields: []string{"_COMM"}}
+
+	bootPrompt := promptui.Select{
+		Label: "🥾 Select a boot",
+		Items: []string{"🟢 Current Boot", "⏮️ Previous Boot", "📚 Choose Boot...", "🗂️ All Boots"},
+	}
+	_, bootSelection, err := bootPrompt.Run()
+	if err != nil {
+		fmt.Printf("Boot selection failed: %v\n", err)
+		return
+	}
+
+	switch bootSelection {
+	case "⏮️ Previous Boot":
+		query.Boot = -1
+	case "📚 Choose Boot...":
+		boot, ok := chooseBoot()
+		if !ok {
+			return
+		}
+		query.Boot = boot
+	case "🗂️ All Boots":
+		query.AllBoots = true
+	}
+
+	labels := make([]string, len(priorityFilters))
+	for i, filter := range priorityFilters {
+		labels[i] = filter.Label
+	}
+	priorityPrompt := promptui.Select{
+		Label: "🎚️ Filter by priority",
+		Items: labels,
+	}
+	priorityIndex, _, err := priorityPrompt.Run()
+	if err != nil {
+		fmt.Printf("Priority selection failed: %v\n", err)
+		return
+	}
+	query.Priority = priorityFilters[priorityIndex].Priority
+
+	entries, err := systemd.GetServiceJournal(service, query)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	if len(entries) == 0 {
+		fmt.Println("No journal entries match.")
+		return
+	}
+
+	for _, entry := range entries {
+		fmt.Println(formatJournalEntry(entry))
+	}
+}
+
+// chooseBoot lets the user pick one of the boots recorded in the journal, newest first
+func chooseBoot() (int, bool) {
+	boots, err := systemd.ListBoots()
+	if err != nil {
+		fmt.Println(err)
+		return 0, false
+	}
+	if len(boots) == 0 {
+		fmt.Println("No boots recorded in the journal.")
+		return 0, false
+	}
+
+	sort.Slice(boots, func(i, j int) bool { return boots[i].Offset > boots[j].Offset })
+	options := make([]string, len(boots))
+	for i, boot := range boots {
+		options[i] = fmt.Sprintf("%3d  %s", boot.Offset, boot.ID)
+		if !boot.First.IsZero() {
+			options[i] += fmt.Sprintf("  %s — %s", boot.First.Format("2006-01-02 15:04"), boot.Last.Format("2006-01-02 15:04"))
+		}
+	}
+
+	bootPrompt := promptui.Select{
+		Label: "🥾 Select a boot",
+		Items: options,
+		Size:  10,
+	}
+	index, _, err := bootPrompt.Run()
+	if err != nil {
+		fmt.Printf("Boot selection failed: %v\n", err)
+		return 0, false
+	}
+	return boots[index].Offset, true
+}
+
+// formatJournalEntry prints an entry like journalctl's short format, colored by priority
+func formatJournalEntry(entry models.JournalEntry) string {
+	source := entry.Identifier
+	if source == "" {
+		source = entry.Fields["_COMM"]
+	}
+	if entry.PID != 0 {
+		source = fmt.Sprintf("%s[%d]", source, entry.PID)
+	}
+
+	priority := "?"
+	if entry.Priority >= 0 && entry.Priority < len(systemd.PriorityNames) {
+		priority = systemd.PriorityNames[entry.Priority]
+	}
+
+	line := fmt.Sprintf("%s %-7s %s: %s", entry.Timestamp.Format("2006-01-02 15:04:05"), priority, source,
+		strings.TrimRight(entry.Message, "\n"))
+	return priorityStyle(entry.Priority)(line)
+}
+
+// priorityStyle returns the terminal style for a priority
+func priorityStyle(priority int) func(interface{}) string {
+	switch {
+	case priority <= systemd.PriorityErr:
+		return promptui.Styler(promptui.FGRed, promptui.FGBold)
+	case priority == systemd.PriorityWarning:
+		return promptui.Styler(promptui.FGYellow)
+	case priority == systemd.PriorityNotice:
+		return promptui.Styler(promptui.FGCyan)
+	case priority == systemd.PriorityDebug:
+		return promptui.Styler(promptui.FGFaint)
+	default:
+		return func(text interface{}) string { return fmt.Sprint(text) }
+	}
+}
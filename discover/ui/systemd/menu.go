@@ -2,17 +2,29 @@ package systemdUI
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/manifoldco/promptui"
 	"discover/agents/systemd"
+	"discover/models"
+	"discover/ui/utils"
 )
 
 // ShowSystemdMenu handles the systemd service menu
-func ShowSystemdMenu(serviceName string) {
+func ShowSystemdMenu(service models.SystemdService) {
+	serviceName := service.Name
+	
 	// Create a prompt for service actions
 	actionPrompt := promptui.Select{
 		Label: fmt.Sprintf("🔍 Select an action for service '%s'", serviceName),
-		Items: []string{"📜 View Logs", "📊 View Details", "🔄 Restart Service", "⬅️ Back"},
+		Items: []string{
+			"📜 View Logs", "📊 View Details", "🕸️ Dependencies",
+			"📄 View Unit File", "✏️ Edit Override",
+			"▶️ Start", "⏹️ Stop", "🔄 Restart Service", "🔃 Reload",
+			"✅ Enable", "🚫 Disable", "🙈 Mask", "👁️ Unmask", "♻️ Daemon Reload",
+			"⬅️ Back",
+		},
 	}
 	
 	_, actionSelection, err := actionPrompt.Run()
@@ -28,17 +40,17 @@ func ShowSystemdMenu(serviceName string) {
 	
 	switch actionSelection {
 	case "📜 View Logs":
-		logs := systemd.GetSystemdServiceLogs(serviceName)
-		fmt.Println(logs)
+		showJournal(service)
 		
 	case "📊 View Details":
-		details, err := systemd.GetSystemdServiceStatus(serviceName)
+		details, err := systemd.GetServiceStatus(service)
 		if err != nil {
 			fmt.Println(err)
 			return
 		}
 		
 		fmt.Printf("Service: %s\n", details.Id)
+		printScope(service.Scope, service.User)
 		fmt.Printf("Description: %s\n", details.Description)
 		fmt.Printf("Load State: %s\n", details.LoadState)
 		fmt.Printf("Active State: %s\n", details.ActiveState)
@@ -48,13 +60,165 @@ func ShowSystemdMenu(serviceName string) {
 		fmt.Printf("Main Status: %s\n", details.ExecMainStatus)
 		fmt.Printf("Type: %s\n", details.Type)
 		fmt.Printf("Restart: %s\n", details.Restart)
+		fmt.Printf("Restarts: %d\n", details.NRestarts)
+		fmt.Printf("Active Since: %s\n", utilsUI.FormatTime(details.ActiveEnterTimestamp))
+		fmt.Printf("Main Process Started: %s\n", utilsUI.FormatTime(details.ExecMainStartTimestamp))
+		if details.User != "" {
+			fmt.Printf("User: %s\n", details.User)
+		}
+		
+		fmt.Println("Resources:")
+		fmt.Printf("  Memory: %s (peak %s)\n", accountedBytes(details.MemoryCurrent), accountedBytes(details.MemoryPeak))
+		fmt.Printf("  CPU Time: %s\n", time.Duration(details.CPUUsageNSec).Round(time.Millisecond))
+		fmt.Printf("  Tasks: %d\n", details.TasksCurrent)
+		fmt.Printf("  IO: %s read, %s written\n", accountedBytes(details.IOReadBytes), accountedBytes(details.IOWriteBytes))
+		
+		fmt.Printf("Unit File: %s\n", details.FragmentPath)
+		for _, dropIn := range details.DropInPaths {
+			fmt.Printf("  Drop-In: %s\n", dropIn)
+		}
+		if len(details.Environment) > 0 {
+			fmt.Println("Environment:")
+			for _, assignment := range details.Environment {
+				fmt.Printf("  %s\n", assignment)
+			}
+		}
+		
+	case "🕸️ Dependencies":
+		showDependencies(service)
 		
-	case "🔄 Restart Service":
-		fmt.Printf("Restarting service %s...\n", serviceName)
-		if err := systemd.RestartSystemdService(serviceName); err != nil {
+	case "📄 View Unit File":
+		unitFile, err := systemd.GetUnitFile(service)
+		if err != nil {
 			fmt.Println(err)
-		} else {
-			fmt.Printf("Service %s restarted successfully\n", serviceName)
+			return
+		}
+		fmt.Println(unitFile)
+		
+	case "✏️ Edit Override":
+		editOverride(service)
+		
+	default:
+		if action, ok := serviceActions[actionSelection]; ok {
+			runServiceAction(service, action)
 		}
 	}
-}
\ No newline at end of file
+}
+
+// serviceActions maps the control menu items to systemd actions
+var serviceActions = map[string]string{
+	"▶️ Start":           systemd.ActionStart,
+	"⏹️ Stop":            systemd.ActionStop,
+	"🔄 Restart Service": systemd.ActionRestart,
+	"🔃 Reload":          systemd.ActionReload,
+	"✅ Enable":          systemd.ActionEnable,
+	"🚫 Disable":         systemd.ActionDisable,
+	"🙈 Mask":            systemd.ActionMask,
+	"👁️ Unmask":         systemd.ActionUnmask,
+	"♻️ Daemon Reload":   systemd.ActionDaemonReload,
+}
+
+// runServiceAction confirms a control action and performs it
+func runServiceAction(service models.SystemdService, action string) {
+	label := fmt.Sprintf("%s service '%s'", action, service.Name)
+	if action == systemd.ActionDaemonReload {
+		label = "Reload all unit files of the manager"
+		if service.Scope == systemd.ScopeUser {
+			label = fmt.Sprintf("Reload all unit files of the user manager of %s", service.User)
+		}
+	}
+	
+	confirmPrompt := promptui.Prompt{
+		Label:     strings.ToUpper(label[:1]) + label[1:],
+		IsConfirm: true,
+	}
+	if _, err := confirmPrompt.Run(); err != nil {
+		fmt.Println("Cancelled.")
+		return
+	}
+	
+	performServiceAction(service, action)
+}
+
+// performServiceAction performs a control action, offering polkit or sudo when the manager
+// refuses it, and reports the state the service ended up in
+func performServiceAction(service models.SystemdService, action string) {
+	fmt.Printf("Running %s on %s...\n", action, service.Name)
+	result, err := systemd.ControlService(service, action)
+	if systemd.IsAuthorizationError(err) {
+		fmt.Println(err)
+		result, err = escalateServiceAction(service, action)
+	}
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	
+	printActionResult(result)
+}
+
+// editOverride edits the override drop-in of a service and reloads its manager when the
+// validated override changed
+func editOverride(service models.SystemdService) {
+	path, err := systemd.OverridePath(service)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	
+	changed, err := systemd.EditOverride(service)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	if !changed {
+		fmt.Println("No changes made.")
+		return
+	}
+	
+	fmt.Printf("Override saved to %s\n", path)
+	performServiceAction(service, systemd.ActionDaemonReload)
+}
+
+// escalateServiceAction asks how to authenticate for an action the manager refused
+func escalateServiceAction(service models.SystemdService, action string) (models.SystemdActionResult, error) {
+	options := []string{"🔐 Authenticate with polkit"}
+	if service.Scope != systemd.ScopeUser && systemd.SudoAvailable() {
+		options = append(options, "🛡️ Retry with sudo")
+	}
+	options = append(options, "❌ Cancel")
+	
+	escalationPrompt := promptui.Select{
+		Label: "🔒 Authentication is required",
+		Items: options,
+	}
+	
+	_, selection, err := escalationPrompt.Run()
+	if err != nil || selection == "❌ Cancel" {
+		return models.SystemdActionResult{}, fmt.Errorf("%s of %s was not performed", action, service.Name)
+	}
+	
+	method := systemd.EscalatePolkit
+	if selection == "🛡️ Retry with sudo" {
+		method = systemd.EscalateSudo
+	}
+	return systemd.ControlServiceEscalated(service, action, method)
+}
+
+// printActionResult reports whether the unit reached the state the action should lead to
+func printActionResult(result models.SystemdActionResult) {
+	state := fmt.Sprintf("%s (%s), unit file %s", result.ActiveState, result.SubState, result.UnitFileState)
+	if result.Reached {
+		fmt.Printf("✅ %s: %s succeeded, now %s\n", result.Unit, result.Action, state)
+	} else {
+		fmt.Printf("⚠️ %s: %s did not reach %s, now %s\n", result.Unit, result.Action, result.Expected, state)
+	}
+}
+
+// accountedBytes prints a byte count, or "n/a" when systemd accounted nothing
+func accountedBytes(bytes uint64) string {
+	if bytes == 0 {
+		return "n/a"
+	}
+	return utilsUI.FormatBytes(bytes)
+}
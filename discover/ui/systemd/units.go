@@ -0,0 +1,171 @@
+package systemdUI
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/manifoldco/promptui"
+	"discover/agents/systemd"
+	"discover/models"
+	"discover/ui/utils"
+)
+
+// ShowUnitBrowser lets the user browse systemd timers, sockets, mounts and targets
+func ShowUnitBrowser() {
+	for {
+		typePrompt := promptui.Select{
+			Label: "🔍 Select a unit type",
+			Items: []string{"⏰ Timers", "🔌 Sockets", "💾 Mounts", "🎯 Targets", "⬅️ Back"},
+		}
+
+		_, typeSelection, err := typePrompt.Run()
+		if err != nil {
+			fmt.Printf("Unit type selection failed: %v\n", err)
+			return
+		}
+
+		switch typeSelection {
+		case "⏰ Timers":
+			fmt.Println("Searching for systemd timers...")
+			timers := systemd.GetSystemdTimers()
+			labels := make([]string, len(timers))
+			for i, timer := range timers {
+				labels[i] = fmt.Sprintf("⏰ %s (%s, last run: %s)",
+					unitLabel(timer.Name, timer.Scope, timer.User), timer.Status, lastRun(timer))
+			}
+			selectUnits("timer", labels, func(i int) { printTimer(timers[i]) })
+
+		case "🔌 Sockets":
+			fmt.Println("Searching for systemd sockets...")
+			sockets := systemd.GetSystemdSockets()
+			labels := make([]string, len(sockets))
+			for i, socket := range sockets {
+				labels[i] = fmt.Sprintf("🔌 %s (%s)", unitLabel(socket.Name, socket.Scope, socket.User), socket.Status)
+			}
+			selectUnits("socket", labels, func(i int) { printSocket(sockets[i]) })
+
+		case "💾 Mounts":
+			fmt.Println("Searching for systemd mounts...")
+			mounts := systemd.GetSystemdMounts()
+			labels := make([]string, len(mounts))
+			for i, mount := range mounts {
+				labels[i] = fmt.Sprintf("💾 %s (%s)", unitLabel(mount.Name, mount.Scope, mount.User), mount.Status)
+			}
+			selectUnits("mount", labels, func(i int) { printMount(mounts[i]) })
+
+		case "🎯 Targets":
+			fmt.Println("Searching for systemd targets...")
+			targets := systemd.GetSystemdTargets()
+			labels := make([]string, len(targets))
+			for i, target := range targets {
+				labels[i] = fmt.Sprintf("🎯 %s (%s)", unitLabel(target.Name, target.Scope, target.User), target.Status)
+			}
+			selectUnits("target", labels, func(i int) { printTarget(targets[i]) })
+
+		default:
+			return
+		}
+	}
+}
+
+// selectUnits prompts for one of the listed units and shows it until the user goes back
+func selectUnits(unitType string, labels []string, show func(int)) {
+	if len(labels) == 0 {
+		fmt.Printf("No %s units found.\n", unitType)
+		return
+	}
+
+	options := append([]string{"⬅️ Back"}, labels...)
+	for {
+		unitPrompt := promptui.Select{
+			Label: fmt.Sprintf("🔍 Select a %s", unitType),
+			Items: options,
+			Size:  15,
+		}
+
+		index, _, err := unitPrompt.Run()
+		if err != nil {
+			fmt.Printf("Unit selection failed: %v\n", err)
+			return
+		}
+		if index == 0 {
+			return
+		}
+
+		show(index - 1)
+	}
+}
+
+// unitLabel prefixes units of user managers with their owner
+func unitLabel(name, scope, user string) string {
+	if scope == systemd.ScopeUser {
+		return fmt.Sprintf("(user %s) %s", user, name)
+	}
+	return name
+}
+
+// printScope prints which manager a unit belongs to
+func printScope(scope, user string) {
+	if scope == systemd.ScopeUser {
+		fmt.Printf("Scope: user (%s)\n", user)
+	} else {
+		fmt.Println("Scope: system")
+	}
+}
+
+// lastRun summarizes when a timer last fired and how the run ended
+func lastRun(timer models.SystemdTimer) string {
+	if timer.LastTrigger.IsZero() {
+		return "never"
+	}
+	run := utilsUI.FormatTime(timer.LastTrigger)
+	if timer.LastResult != "" {
+		run += ", " + timer.LastResult
+	}
+	return run
+}
+
+// printTimer prints the details of a timer unit
+func printTimer(timer models.SystemdTimer) {
+	fmt.Printf("Timer: %s\n", timer.Name)
+	printScope(timer.Scope, timer.User)
+	fmt.Printf("Description: %s\n", timer.Description)
+	fmt.Printf("Status: %s\n", timer.Status)
+	fmt.Printf("Activates: %s\n", timer.Unit)
+	fmt.Printf("Next Run: %s\n", utilsUI.FormatTime(timer.NextElapse))
+	fmt.Printf("Last Run: %s\n", utilsUI.FormatTime(timer.LastTrigger))
+	if timer.LastResult != "" {
+		fmt.Printf("Last Result: %s\n", timer.LastResult)
+	}
+}
+
+// printSocket prints the details of a socket unit
+func printSocket(socket models.SystemdSocket) {
+	fmt.Printf("Socket: %s\n", socket.Name)
+	printScope(socket.Scope, socket.User)
+	fmt.Printf("Description: %s\n", socket.Description)
+	fmt.Printf("Status: %s\n", socket.Status)
+	fmt.Printf("Activates: %s\n", socket.Unit)
+	if len(socket.Listen) > 0 {
+		fmt.Printf("Listen: %s\n", strings.Join(socket.Listen, "\n        "))
+	}
+}
+
+// printMount prints the details of a mount unit
+func printMount(mount models.SystemdMount) {
+	fmt.Printf("Mount: %s\n", mount.Name)
+	printScope(mount.Scope, mount.User)
+	fmt.Printf("Description: %s\n", mount.Description)
+	fmt.Printf("Status: %s\n", mount.Status)
+	fmt.Printf("Where: %s\n", mount.Where)
+	fmt.Printf("What: %s\n", mount.What)
+	fmt.Printf("Type: %s\n", mount.Type)
+}
+
+// printTarget prints the details of a target unit
+func printTarget(target models.SystemdTarget) {
+	fmt.Printf("Target: %s\n", target.Name)
+	printScope(target.Scope, target.User)
+	fmt.Printf("Description: %s\n", target.Description)
+	fmt.Printf("Status: %s\n", target.Status)
+}
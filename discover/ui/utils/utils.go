@@ -0,0 +1,38 @@
+package utilsUI
+
+import (
+	"fmt"
+	"time"
+)
+
+// PauseForUser pauses the program until the user presses Enter
+func PauseForUser() {
+	fmt.Println("\nPress Enter to continue...")
+	fmt.Scanln()
+}
+
+// FormatBytes formats a byte count with a binary unit
+func FormatBytes(bytes uint64) string {
+	units := []string{"B", "KiB", "MiB", "GiB", "TiB"}
+	value := float64(bytes)
+	unit := 0
+	for value >= 1024 && unit < len(units)-1 {
+		value /= 1024
+		unit++
+	}
+	if unit == 0 {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	return fmt.Sprintf("%.1f %s", value, units[unit])
+}
+
+// FormatTime prints a timestamp along with how far away it is, or "n/a" when it is not set
+func FormatTime(t time.Time) string {
+	if t.IsZero() {
+		return "n/a"
+	}
+	if t.After(time.Now()) {
+		return fmt.Sprintf("%s (in %s)", t.Format("2006-01-02 15:04:05"), time.Until(t).Round(time.Second))
+	}
+	return fmt.Sprintf("%s (%s ago)", t.Format("2006-01-02 15:04:05"), time.Since(t).Round(time.Second))
+}